@@ -1,9 +1,12 @@
 package accountsummary
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"iter"
 	"net/mail"
+	"time"
 
 	"github.com/govalues/decimal"
 
@@ -14,6 +17,9 @@ import (
 var (
 	ErrNoTransactions = errors.New("no transactions")
 	ErrInvalidEmail   = errors.New("invalid email")
+	// ErrEmailNotSent is returned when the summary is stored but its email could not be sent. The email stays in the
+	// Outbox, to be retried by a Dispatcher.
+	ErrEmailNotSent = errors.New("email not sent, kept in the outbox")
 )
 
 type (
@@ -23,6 +29,17 @@ type (
 		TransactionsReader TransactionsReader
 		EmailSender        EmailSender
 		Repository         Repository
+		// Recipients is only used by RunBatch, to find the email of each account.
+		Recipients RecipientDirectory
+		// OnDuplicate decides what happens when the source was already summarized for the email.
+		// Duplicates are skipped by default.
+		OnDuplicate DuplicatePolicy
+		// Force processes the source again even if it was already summarized for the email.
+		Force bool
+		// Outbox is set when the Repository queues the email of each summary along with it. Emails are then sent
+		// from the outbox once the execution is committed, and the ones that fail stay there to be retried by a
+		// Dispatcher. Without it, emails are sent right after the execution is stored.
+		Outbox Outbox
 	}
 
 	App struct {
@@ -39,7 +56,9 @@ func New(config Config) App {
 	return App{Config: config}
 }
 
-func (app App) Run() error {
+// Run stops at the first error, including the cancellation of the context. The execution is then not stored, except
+// with ErrEmailNotSent.
+func (app App) Run(ctx context.Context) error {
 	fail := func(err error) error {
 		return fmt.Errorf("app: App: Run: %w", err)
 	}
@@ -48,69 +67,144 @@ func (app App) Run() error {
 		return fail(ErrInvalidEmail)
 	}
 
-	transactions, err := app.TransactionsReader.ReadTransactions()
+	execution, err := app.newExecution(ctx, app.TransactionsReader.StreamTransactions(ctx))
 	if err != nil {
 		return fail(err)
 	}
 
-	if validTransactions(transactions) {
-		if err = app.processTransactions(transactions); err != nil {
-			return fail(err)
-		}
-	} else {
-		return fail(ErrNoTransactions)
+	err = app.summarize(ctx, app.Email, "", execution)
+	if errors.Is(err, ErrAlreadyProcessed) && app.OnDuplicate == SkipDuplicates {
+		return nil
+	}
+
+	if err != nil {
+		return fail(err)
 	}
 
 	return nil
 }
 
-func isInvalidEmail(email string) bool {
-	_, err := mail.ParseAddress(email)
-	return err != nil
-}
+// newExecution starts an execution over the transactions, fingerprinting the source when the reader can.
+func (app App) newExecution(ctx context.Context, transactions iter.Seq2[model.Transaction, error]) (Execution, error) {
+	execution := Execution{
+		Transactions: transactions,
+		FilePath:     app.FilePath,
+		StartedAt:    time.Now().UTC(),
+	}
 
-func validTransactions(transactions []model.Transaction) bool {
-	return len(transactions) > 0
+	if checksummer, ok := app.TransactionsReader.(Checksummer); ok {
+		checksum, err := checksummer.Checksum(ctx)
+		if err != nil {
+			return Execution{}, fmt.Errorf("app: App: newExecution: %w", err)
+		}
+
+		execution.Checksum = checksum
+	}
+
+	return execution, nil
 }
 
-func (app App) processTransactions(transactions []model.Transaction) error {
+// summarize computes the summary of the transactions of the execution, persists it and sends it to the email.
+// The transactions are iterated twice: once to compute the summary and once to persist them.
+// It fails with ErrAlreadyProcessed, before reading any transaction, when the source was already summarized.
+func (app App) summarize(ctx context.Context, email, account string, execution Execution) error {
 	fail := func(err error) error {
-		return fmt.Errorf("app: App: processTransactions: %w", err)
+		return fmt.Errorf("app: App: summarize: %w", err)
 	}
 
-	results, err := trans.Process(transactions)
+	if err := app.checkDuplicate(ctx, email, account, execution); err != nil {
+		return fail(err)
+	}
+
+	results, err := trans.ProcessStream(execution.Transactions)
 	if err != nil {
 		return fail(err)
 	}
 
-	averages, err := calculateAverageAmounts(results)
+	if !validTransactions(results) {
+		return fail(ErrNoTransactions)
+	}
+
+	summary, err := buildSummary(results, email, account)
 	if err != nil {
 		return fail(err)
 	}
 
-	summary := model.AccountSummary{
-		Email:                app.Email,
+	execution.AccountSummary = summary
+	if err = app.processSummary(ctx, execution); err != nil {
+		return fail(err)
+	}
+
+	return nil
+}
+
+func isInvalidEmail(email string) bool {
+	_, err := mail.ParseAddress(email)
+	return err != nil
+}
+
+func validTransactions(results trans.ExecutionResults) bool {
+	return results.TransactionsCount() > 0
+}
+
+func buildSummary(results trans.ExecutionResults, email, account string) (model.AccountSummary, error) {
+	averages, err := calculateAverageAmounts(results)
+	if err != nil {
+		return model.AccountSummary{}, fmt.Errorf("app: buildSummary: %w", err)
+	}
+
+	return model.AccountSummary{
+		Email:                email,
+		Account:              account,
 		TotalBalance:         results.TotalBalance,
 		AverageDebitAmount:   averages.debit,
 		AverageCreditAmount:  averages.credit,
 		TransactionsPerMonth: results.TransactionsPerMonth,
+		MinimumBalance:       results.MinimumBalance,
+		MinimumBalanceDate:   results.MinimumBalanceDate,
+		MaximumBalance:       results.MaximumBalance,
+		MaximumBalanceDate:   results.MaximumBalanceDate,
+		DailyBalances:        results.DailyBalances,
+		MonthlyBreakdown:     results.MonthlyBreakdown,
+	}, nil
+}
+
+func (app App) processSummary(ctx context.Context, execution Execution) error {
+	fail := func(err error) error {
+		return fmt.Errorf("app: App: processSummary: %w", err)
 	}
 
-	if err = app.Repository.Create(Execution{
-		FilePath:       app.FilePath,
-		AccountSummary: summary,
-		Transactions:   transactions,
-	}); err != nil {
+	execution.QueueEmail = app.Outbox != nil
+	summaryID, err := app.Repository.Create(ctx, execution)
+	if err != nil {
 		return fail(err)
 	}
 
-	if err = app.sendEmail(summary); err != nil {
+	if app.Outbox != nil {
+		report, errDispatch := app.dispatcher().DispatchSummary(ctx, summaryID)
+		if errDispatch != nil {
+			return fail(errDispatch)
+		}
+
+		if len(report.Errors) > 0 {
+			return fail(fmt.Errorf("%w: %w", ErrEmailNotSent, errors.Join(report.Errors...)))
+		}
+
+		return nil
+	}
+
+	if err = app.sendEmail(ctx, execution.AccountSummary); err != nil {
 		return fail(err)
 	}
 
 	return nil
 }
 
+// dispatcher sends the email just queued in the outbox with the default retry policy.
+func (app App) dispatcher() Dispatcher {
+	return NewDispatcher(DispatcherConfig{Outbox: app.Outbox, EmailSender: app.EmailSender})
+}
+
 func calculateAverageAmounts(results trans.ExecutionResults) (AverageAmounts, error) {
 	fail := func(err error) (AverageAmounts, error) {
 		return AverageAmounts{}, fmt.Errorf("app: App: calculateAverageAmounts: %w", err)
@@ -142,8 +236,8 @@ func calculateAverageAmounts(results trans.ExecutionResults) (AverageAmounts, er
 	}, nil
 }
 
-func (app App) sendEmail(summary model.AccountSummary) error {
-	if err := app.EmailSender.Send(summary); err != nil {
+func (app App) sendEmail(ctx context.Context, summary model.AccountSummary) error {
+	if err := app.EmailSender.Send(ctx, summary); err != nil {
 		return fmt.Errorf("app: App: sendEmail: %w", err)
 	}
 
@@ -1,6 +1,7 @@
 package accountsummary_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	accsum "stori/accountsummary"
 	mocks "stori/mocks/stori/accountsummary"
 	"stori/model"
+	trans "stori/transactions"
 )
 
 func TestAppRun_NoTransactions(t *testing.T) {
@@ -19,7 +21,7 @@ func TestAppRun_NoTransactions(t *testing.T) {
 
 	// Arrange
 	readerStub := mocks.NewMockTransactionsReader(t)
-	readerStub.EXPECT().ReadTransactions().Return([]model.Transaction{}, nil)
+	readerStub.EXPECT().StreamTransactions(mock.Anything).Return(trans.Seq([]model.Transaction{}))
 
 	sut := accsum.New(accsum.Config{
 		Email:              "john.doe@stori.com",
@@ -27,7 +29,7 @@ func TestAppRun_NoTransactions(t *testing.T) {
 	})
 
 	// Act
-	err := sut.Run()
+	err := sut.Run(context.Background())
 
 	// Assert
 	require.Error(t, err)
@@ -46,15 +48,23 @@ func TestAppRun_WhenSingleTransaction_SuccessEmailSenderCalled(t *testing.T) {
 		AverageDebitAmount:   decimal.Zero,
 		AverageCreditAmount:  decimal.MustParse("100"),
 		TransactionsPerMonth: buildTransactionPerMonth([]time.Month{time.November}, []int{1}),
+		MinimumBalance:       decimal.MustParse("100"),
+		MinimumBalanceDate:   buildDate(time.November),
+		MaximumBalance:       decimal.MustParse("100"),
+		MaximumBalanceDate:   buildDate(time.November),
+		DailyBalances:        []model.DailyBalance{{Date: buildDate(time.November), Balance: decimal.MustParse("100")}},
+		MonthlyBreakdown: []model.PeriodBreakdown{
+			buildPeriodBreakdown(time.November, 1, "100", "100", 0, "0", "0", "100"),
+		},
 	}
 
 	readerStub := mocks.NewMockTransactionsReader(t)
 	emailSenderMock := mocks.NewMockEmailSender(t)
 	repositoryMock := mocks.NewMockRepository(t)
 
-	readerStub.EXPECT().ReadTransactions().Return(transactions, nil)
-	emailSenderMock.EXPECT().Send(expectedSummary).Return(nil)
-	repositoryMock.EXPECT().Create(mock.Anything).Return(nil)
+	readerStub.EXPECT().StreamTransactions(mock.Anything).Return(trans.Seq(transactions))
+	emailSenderMock.EXPECT().Send(mock.Anything, expectedSummary).Return(nil)
+	repositoryMock.EXPECT().Create(mock.Anything, mock.Anything).Return(int64(1), nil)
 
 	sut := accsum.New(accsum.Config{
 		Email:              email,
@@ -64,7 +74,7 @@ func TestAppRun_WhenSingleTransaction_SuccessEmailSenderCalled(t *testing.T) {
 	})
 
 	// Act
-	err := sut.Run()
+	err := sut.Run(context.Background())
 
 	// Assert
 	require.NoError(t, err)
@@ -92,15 +102,32 @@ func TestAppRun_WhenSeveralTransactions_SuccessEmailSenderCalled(t *testing.T) {
 		AverageCreditAmount: decimal.MustNew(134330382, 2),
 		TransactionsPerMonth: buildTransactionPerMonth(
 			[]time.Month{time.February, time.April, time.November, time.December}, []int{2, 1, 4, 1}),
+		MinimumBalance:     decimal.MustParse("-186132.31"),
+		MinimumBalanceDate: buildDate(time.November),
+		MaximumBalance:     decimal.MustParse("2867415.21"),
+		MaximumBalanceDate: buildDate(time.November),
+		DailyBalances: []model.DailyBalance{
+			{Date: buildDate(time.February), Balance: decimal.MustParse("2810237.08")},
+			{Date: buildDate(time.April), Balance: decimal.MustParse("1472216.86")},
+			{Date: buildDate(time.November), Balance: decimal.MustParse("1232351.49")},
+			{Date: buildDate(time.December), Balance: decimal.MustParse("2324951.36")},
+		},
+		MonthlyBreakdown: []model.PeriodBreakdown{
+			buildPeriodBreakdown(time.February, 2, "2810237.08", "1405118.54", 0, "0", "0", "2810237.08"),
+			buildPeriodBreakdown(time.April, 0, "0", "0", 1, "-1338020.22", "-1338020.22", "-1338020.22"),
+			buildPeriodBreakdown(time.November,
+				2, "2813682.15", "1406841.075", 2, "-3053547.52", "-1526773.76", "-239865.37"),
+			buildPeriodBreakdown(time.December, 1, "1092599.87", "1092599.87", 0, "0", "0", "1092599.87"),
+		},
 	}
 
 	readerStub := mocks.NewMockTransactionsReader(t)
 	emailSenderMock := mocks.NewMockEmailSender(t)
 	repositoryMock := mocks.NewMockRepository(t)
 
-	readerStub.EXPECT().ReadTransactions().Return(transactions, nil)
-	emailSenderMock.EXPECT().Send(expectedSummary).Return(nil).Once()
-	repositoryMock.EXPECT().Create(mock.Anything).Return(nil)
+	readerStub.EXPECT().StreamTransactions(mock.Anything).Return(trans.Seq(transactions))
+	emailSenderMock.EXPECT().Send(mock.Anything, expectedSummary).Return(nil).Once()
+	repositoryMock.EXPECT().Create(mock.Anything, mock.Anything).Return(int64(1), nil)
 
 	sut := accsum.New(accsum.Config{
 		Email:              email,
@@ -110,7 +137,7 @@ func TestAppRun_WhenSeveralTransactions_SuccessEmailSenderCalled(t *testing.T) {
 	})
 
 	// Act
-	err := sut.Run()
+	err := sut.Run(context.Background())
 
 	// Assert
 	require.NoError(t, err)
@@ -131,7 +158,7 @@ func TestAppRun_WhenInvalidEmail_Error(t *testing.T) {
 	})
 
 	// Act
-	err := sut.Run()
+	err := sut.Run(context.Background())
 
 	// Assert
 	require.Error(t, err)
@@ -141,17 +168,202 @@ func TestAppRun_WhenInvalidEmail_Error(t *testing.T) {
 func buildTransaction(id int, month time.Month, value string) model.Transaction {
 	return model.Transaction{
 		ID:     id,
-		Date:   time.Date(2024, month, 1, 0, 0, 0, 0, time.UTC),
+		Date:   buildDate(month),
 		Amount: decimal.MustParse(value),
 	}
 }
 
-func buildTransactionPerMonth(nonZeroMonths []time.Month, countsPerMonth []int) map[time.Month]int {
-	transactionsPerMonth := make(map[time.Month]int)
+func buildDate(month time.Month) time.Time {
+	return time.Date(2024, month, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func buildTransactionPerMonth(nonZeroMonths []time.Month, countsPerMonth []int) map[model.YearMonth]int {
+	transactionsPerMonth := make(map[model.YearMonth]int)
 
 	for i, month := range nonZeroMonths {
-		transactionsPerMonth[month] = countsPerMonth[i]
+		transactionsPerMonth[model.YearMonth{Year: 2024, Month: month}] = countsPerMonth[i]
 	}
 
 	return transactionsPerMonth
 }
+
+func buildPeriodBreakdown(month time.Month, creditCount int, totalCredit, avgCredit string,
+	debitCount int, totalDebit, avgDebit, netFlow string,
+) model.PeriodBreakdown {
+	return model.PeriodBreakdown{
+		Period:              model.YearMonth{Year: 2024, Month: month},
+		CreditCount:         creditCount,
+		DebitCount:          debitCount,
+		TotalCredit:         decimal.MustParse(totalCredit),
+		TotalDebit:          decimal.MustParse(totalDebit),
+		NetFlow:             decimal.MustParse(netFlow),
+		AverageCreditAmount: decimal.MustParse(avgCredit),
+		AverageDebitAmount:  decimal.MustParse(avgDebit),
+	}
+}
+
+func TestAppRun_WhenReaderHasChecksum_RecordedInExecution(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	transactions := []model.Transaction{buildTransaction(1, time.November, "100")}
+
+	readerStub := checksumReader{
+		MockTransactionsReader: mocks.NewMockTransactionsReader(t),
+		MockChecksummer:        mocks.NewMockChecksummer(t),
+	}
+	emailSenderMock := mocks.NewMockEmailSender(t)
+	repositoryMock := mocks.NewMockRepository(t)
+
+	readerStub.MockTransactionsReader.EXPECT().StreamTransactions(mock.Anything).Return(trans.Seq(transactions))
+	readerStub.MockChecksummer.EXPECT().Checksum(mock.Anything).Return("sha256:abc", nil)
+	emailSenderMock.EXPECT().Send(mock.Anything, mock.Anything).Return(nil)
+	repositoryMock.EXPECT().WasProcessed(mock.Anything, "john.doe@stori.com", "", "sha256:abc").Return(false, nil)
+	repositoryMock.EXPECT().Create(mock.Anything, mock.MatchedBy(func(execution accsum.Execution) bool {
+		return execution.Checksum == "sha256:abc" && execution.FilePath == "transactions.csv" &&
+			!execution.StartedAt.IsZero() && !execution.QueueEmail
+	})).Return(int64(1), nil).Once()
+
+	sut := accsum.New(accsum.Config{
+		Email:              "john.doe@stori.com",
+		FilePath:           "transactions.csv",
+		TransactionsReader: readerStub,
+		EmailSender:        emailSenderMock,
+		Repository:         repositoryMock,
+	})
+
+	// Act
+	err := sut.Run(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+}
+
+func TestAppRun_WhenAlreadyProcessed(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		onDuplicate accsum.DuplicatePolicy
+		force       bool
+		expectedErr error
+		processed   bool
+	}{
+		{name: "skipped by default", onDuplicate: accsum.SkipDuplicates},
+		{name: "rejected", onDuplicate: accsum.RejectDuplicates, expectedErr: accsum.ErrAlreadyProcessed},
+		{name: "processed again when forced", onDuplicate: accsum.RejectDuplicates, force: true, processed: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			email := "john.doe@stori.com"
+			transactions := []model.Transaction{buildTransaction(1, time.November, "100")}
+
+			readerStub := checksumReader{
+				MockTransactionsReader: mocks.NewMockTransactionsReader(t),
+				MockChecksummer:        mocks.NewMockChecksummer(t),
+			}
+			emailSenderMock := mocks.NewMockEmailSender(t)
+			repositoryMock := mocks.NewMockRepository(t)
+
+			readerStub.MockTransactionsReader.EXPECT().StreamTransactions(mock.Anything).Return(trans.Seq(transactions))
+			readerStub.MockChecksummer.EXPECT().Checksum(mock.Anything).Return("sha256:abc", nil)
+			if tc.processed {
+				repositoryMock.EXPECT().Create(mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+				emailSenderMock.EXPECT().Send(mock.Anything, mock.Anything).Return(nil).Once()
+			} else {
+				repositoryMock.EXPECT().WasProcessed(mock.Anything, email, "", "sha256:abc").Return(true, nil)
+			}
+
+			sut := accsum.New(accsum.Config{
+				Email:              email,
+				TransactionsReader: readerStub,
+				EmailSender:        emailSenderMock,
+				Repository:         repositoryMock,
+				OnDuplicate:        tc.onDuplicate,
+				Force:              tc.force,
+			})
+
+			// Act
+			err := sut.Run(context.Background())
+
+			// Assert
+			if tc.expectedErr != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestAppRun_WhenOutbox_EmailSentFromOutboxAfterCreate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		sendErr     error
+		expectedErr error
+	}{
+		{name: "sent"},
+		{name: "kept for retry when sending fails", sendErr: errSMTP, expectedErr: accsum.ErrEmailNotSent},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			summary := model.AccountSummary{Email: "john.doe@stori.com"}
+			transactions := []model.Transaction{buildTransaction(1, time.November, "100")}
+
+			readerStub := mocks.NewMockTransactionsReader(t)
+			emailSenderMock := mocks.NewMockEmailSender(t)
+			repositoryMock := mocks.NewMockRepository(t)
+			outboxMock := mocks.NewMockOutbox(t)
+
+			readerStub.EXPECT().StreamTransactions(mock.Anything).Return(trans.Seq(transactions))
+			created := repositoryMock.EXPECT().Create(mock.Anything, mock.MatchedBy(func(execution accsum.Execution) bool {
+				return execution.QueueEmail
+			})).Return(int64(3), nil).Call
+			outboxMock.EXPECT().ClaimSummary(mock.Anything, int64(3), mock.Anything).
+				Return([]model.OutboxEmail{{ID: 7, Summary: summary}}, nil).Once().NotBefore(created)
+			emailSenderMock.EXPECT().Send(mock.Anything, summary).Return(tc.sendErr)
+			if tc.sendErr == nil {
+				outboxMock.EXPECT().MarkSent(mock.Anything, int64(7)).Return(nil)
+			} else {
+				outboxMock.EXPECT().MarkRetry(mock.Anything, int64(7), 1, tc.sendErr.Error(), mock.Anything).Return(nil)
+			}
+
+			sut := accsum.New(accsum.Config{
+				Email:              summary.Email,
+				TransactionsReader: readerStub,
+				EmailSender:        emailSenderMock,
+				Repository:         repositoryMock,
+				Outbox:             outboxMock,
+			})
+
+			// Act
+			err := sut.Run(context.Background())
+
+			// Assert
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				assert.ErrorIs(t, err, tc.sendErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+type checksumReader struct {
+	*mocks.MockTransactionsReader
+	*mocks.MockChecksummer
+}
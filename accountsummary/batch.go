@@ -0,0 +1,147 @@
+package accountsummary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"stori/model"
+	trans "stori/transactions"
+)
+
+var (
+	ErrNoRecipients    = errors.New("no recipient directory")
+	ErrMissingAccounts = errors.New("transactions without account")
+)
+
+type (
+	// AccountResult tells how the summary of an account went in a batch run. Err is nil when it was persisted
+	// and sent, or when it was Skipped because the source was already summarized for the account.
+	AccountResult struct {
+		Account string
+		Email   string
+		Skipped bool
+		Err     error
+	}
+
+	// BatchReport lists the result of every account of a batch run, in order of first appearance in the input.
+	BatchReport struct {
+		Results []AccountResult
+	}
+)
+
+// RunBatch computes, persists and sends one summary per account of a consolidated input. The email of each
+// account is found in the Recipients directory.
+// A failing account does not stop the others: its error is in the report. Only errors reading the input fail
+// the whole run. Transactions are grouped by account in memory, unlike Run, which streams them.
+func (app App) RunBatch(ctx context.Context) (BatchReport, error) {
+	fail := func(err error) (BatchReport, error) {
+		return BatchReport{}, fmt.Errorf("app: App: RunBatch: %w", err)
+	}
+
+	if app.Recipients == nil {
+		return fail(ErrNoRecipients)
+	}
+
+	accounts, transactionsByAccount, err := groupByAccount(ctx, app.TransactionsReader)
+	if err != nil {
+		return fail(err)
+	}
+
+	if len(accounts) == 0 {
+		return fail(ErrNoTransactions)
+	}
+
+	// Every account is an execution of its own, over the same source.
+	source, err := app.newExecution(ctx, nil)
+	if err != nil {
+		return fail(err)
+	}
+
+	report := BatchReport{Results: make([]AccountResult, 0, len(accounts))}
+	for _, account := range accounts {
+		execution := source
+		execution.Transactions = trans.Seq(transactionsByAccount[account])
+		report.Results = append(report.Results, app.runAccount(ctx, account, execution))
+	}
+
+	return report, nil
+}
+
+func (app App) runAccount(ctx context.Context, account string, execution Execution) AccountResult {
+	result := AccountResult{Account: account}
+	fail := func(err error) AccountResult {
+		result.Err = fmt.Errorf("app: App: runAccount %q: %w", account, err)
+		return result
+	}
+
+	if account == "" {
+		return fail(ErrMissingAccounts)
+	}
+
+	email, err := app.Recipients.Email(account)
+	if err != nil {
+		return fail(err)
+	}
+	result.Email = email
+
+	if isInvalidEmail(email) {
+		return fail(ErrInvalidEmail)
+	}
+
+	err = app.summarize(ctx, email, account, execution)
+	if errors.Is(err, ErrAlreadyProcessed) && app.OnDuplicate == SkipDuplicates {
+		result.Skipped = true
+		return result
+	}
+
+	if err != nil {
+		return fail(err)
+	}
+
+	return result
+}
+
+func groupByAccount(ctx context.Context, reader TransactionsReader) ([]string, map[string][]model.Transaction, error) {
+	var accounts []string
+	transactionsByAccount := make(map[string][]model.Transaction)
+
+	for transaction, err := range reader.StreamTransactions(ctx) {
+		if err != nil {
+			return nil, nil, fmt.Errorf("app: groupByAccount: %w", err)
+		}
+
+		if _, seen := transactionsByAccount[transaction.Account]; !seen {
+			accounts = append(accounts, transaction.Account)
+		}
+
+		transactionsByAccount[transaction.Account] = append(transactionsByAccount[transaction.Account], transaction)
+	}
+
+	return accounts, transactionsByAccount, nil
+}
+
+// Failed returns the results of the accounts whose summary could not be persisted or sent.
+func (report BatchReport) Failed() []AccountResult {
+	var failed []AccountResult
+	for _, result := range report.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+
+	return failed
+}
+
+// Skipped returns the results of the accounts whose summary was not sent again because the source was already
+// summarized for them.
+func (report BatchReport) Skipped() []AccountResult {
+	var skipped []AccountResult
+	for _, result := range report.Results {
+		if result.Skipped {
+			skipped = append(skipped, result)
+		}
+	}
+
+	return skipped
+}
@@ -0,0 +1,208 @@
+package accountsummary_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	accsum "stori/accountsummary"
+	mocks "stori/mocks/stori/accountsummary"
+	"stori/model"
+	trans "stori/transactions"
+)
+
+var errUnknownAccount = errors.New("unknown account")
+
+func TestAppRunBatch_WhenSeveralAccounts_OneSummaryEach(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	transactions := []model.Transaction{
+		buildAccountTransaction(1, "ACC-1", time.January, "100"),
+		buildAccountTransaction(2, "ACC-2", time.January, "-30"),
+		buildAccountTransaction(3, "ACC-1", time.February, "-40"),
+	}
+
+	readerStub := mocks.NewMockTransactionsReader(t)
+	recipientsStub := mocks.NewMockRecipientDirectory(t)
+	emailSenderMock := mocks.NewMockEmailSender(t)
+	repositoryMock := mocks.NewMockRepository(t)
+
+	readerStub.EXPECT().StreamTransactions(mock.Anything).Return(trans.Seq(transactions))
+	recipientsStub.EXPECT().Email("ACC-1").Return("john.doe@stori.com", nil)
+	recipientsStub.EXPECT().Email("ACC-2").Return("jane.doe@stori.com", nil)
+	repositoryMock.EXPECT().Create(mock.Anything, mock.Anything).Return(int64(1), nil).Times(2)
+	emailSenderMock.EXPECT().Send(mock.Anything, mock.MatchedBy(func(summary model.AccountSummary) bool {
+		return summary.Account == "ACC-1" && summary.Email == "john.doe@stori.com" &&
+			summary.TotalBalance.String() == "60"
+	})).Return(nil).Once()
+	emailSenderMock.EXPECT().Send(mock.Anything, mock.MatchedBy(func(summary model.AccountSummary) bool {
+		return summary.Account == "ACC-2" && summary.Email == "jane.doe@stori.com" &&
+			summary.TotalBalance.String() == "-30"
+	})).Return(nil).Once()
+
+	sut := accsum.New(accsum.Config{
+		TransactionsReader: readerStub,
+		EmailSender:        emailSenderMock,
+		Repository:         repositoryMock,
+		Recipients:         recipientsStub,
+	})
+
+	// Act
+	report, err := sut.RunBatch(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []accsum.AccountResult{
+		{Account: "ACC-1", Email: "john.doe@stori.com"},
+		{Account: "ACC-2", Email: "jane.doe@stori.com"},
+	}, report.Results)
+	assert.Empty(t, report.Failed())
+}
+
+func TestAppRunBatch_WhenAccountFails_OthersAreSent(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	transactions := []model.Transaction{
+		buildAccountTransaction(1, "ACC-1", time.January, "100"),
+		buildAccountTransaction(2, "ACC-2", time.January, "-30"),
+		buildAccountTransaction(3, "ACC-3", time.January, "10"),
+		buildAccountTransaction(4, "", time.January, "10"),
+	}
+
+	readerStub := mocks.NewMockTransactionsReader(t)
+	recipientsStub := mocks.NewMockRecipientDirectory(t)
+	emailSenderMock := mocks.NewMockEmailSender(t)
+	repositoryMock := mocks.NewMockRepository(t)
+
+	readerStub.EXPECT().StreamTransactions(mock.Anything).Return(trans.Seq(transactions))
+	recipientsStub.EXPECT().Email("ACC-1").Return("", errUnknownAccount)
+	recipientsStub.EXPECT().Email("ACC-2").Return("invalid-email", nil)
+	recipientsStub.EXPECT().Email("ACC-3").Return("john.doe@stori.com", nil)
+	repositoryMock.EXPECT().Create(mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+	emailSenderMock.EXPECT().Send(mock.Anything, mock.Anything).Return(nil).Once()
+
+	sut := accsum.New(accsum.Config{
+		TransactionsReader: readerStub,
+		EmailSender:        emailSenderMock,
+		Repository:         repositoryMock,
+		Recipients:         recipientsStub,
+	})
+
+	// Act
+	report, err := sut.RunBatch(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, report.Results, 4)
+
+	failed := report.Failed()
+	require.Len(t, failed, 3)
+	assert.Equal(t, "ACC-1", failed[0].Account)
+	assert.ErrorIs(t, failed[0].Err, errUnknownAccount)
+	assert.Equal(t, "ACC-2", failed[1].Account)
+	assert.ErrorIs(t, failed[1].Err, accsum.ErrInvalidEmail)
+	assert.Equal(t, "", failed[2].Account)
+	assert.ErrorIs(t, failed[2].Err, accsum.ErrMissingAccounts)
+	assert.NoError(t, report.Results[2].Err)
+}
+
+func TestAppRunBatch_WhenAccountAlreadyProcessed_OnlyOthersAreSent(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	transactions := []model.Transaction{
+		buildAccountTransaction(1, "ACC-1", time.January, "100"),
+		buildAccountTransaction(2, "ACC-2", time.January, "-30"),
+	}
+
+	readerStub := checksumReader{
+		MockTransactionsReader: mocks.NewMockTransactionsReader(t),
+		MockChecksummer:        mocks.NewMockChecksummer(t),
+	}
+	recipientsStub := mocks.NewMockRecipientDirectory(t)
+	emailSenderMock := mocks.NewMockEmailSender(t)
+	repositoryMock := mocks.NewMockRepository(t)
+
+	readerStub.MockTransactionsReader.EXPECT().StreamTransactions(mock.Anything).Return(trans.Seq(transactions))
+	readerStub.MockChecksummer.EXPECT().Checksum(mock.Anything).Return("sha256:abc", nil)
+	recipientsStub.EXPECT().Email("ACC-1").Return("john.doe@stori.com", nil)
+	recipientsStub.EXPECT().Email("ACC-2").Return("jane.doe@stori.com", nil)
+	repositoryMock.EXPECT().WasProcessed(mock.Anything, "john.doe@stori.com", "ACC-1", "sha256:abc").Return(true, nil)
+	repositoryMock.EXPECT().WasProcessed(mock.Anything, "jane.doe@stori.com", "ACC-2", "sha256:abc").Return(false, nil)
+	repositoryMock.EXPECT().Create(mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+	emailSenderMock.EXPECT().Send(mock.Anything, mock.MatchedBy(func(summary model.AccountSummary) bool {
+		return summary.Account == "ACC-2"
+	})).Return(nil).Once()
+
+	sut := accsum.New(accsum.Config{
+		TransactionsReader: readerStub,
+		EmailSender:        emailSenderMock,
+		Repository:         repositoryMock,
+		Recipients:         recipientsStub,
+	})
+
+	// Act
+	report, err := sut.RunBatch(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, report.Failed())
+	assert.Equal(t, []accsum.AccountResult{
+		{Account: "ACC-1", Email: "john.doe@stori.com", Skipped: true},
+	}, report.Skipped())
+}
+
+func TestAppRunBatch_WhenNoRecipients_Error(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	sut := accsum.New(accsum.Config{
+		TransactionsReader: mocks.NewMockTransactionsReader(t),
+	})
+
+	// Act
+	_, err := sut.RunBatch(context.Background())
+
+	// Assert
+	require.Error(t, err)
+	assert.ErrorIs(t, err, accsum.ErrNoRecipients)
+}
+
+func TestAppRunBatch_WhenReadingFails_Error(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	errRead := errors.New("read error")
+	readerStub := mocks.NewMockTransactionsReader(t)
+	readerStub.EXPECT().StreamTransactions(mock.Anything).Return(func(yield func(model.Transaction, error) bool) {
+		if yield(buildAccountTransaction(1, "ACC-1", time.January, "100"), nil) {
+			yield(model.Transaction{}, errRead)
+		}
+	})
+
+	sut := accsum.New(accsum.Config{
+		TransactionsReader: readerStub,
+		Recipients:         mocks.NewMockRecipientDirectory(t),
+	})
+
+	// Act
+	_, err := sut.RunBatch(context.Background())
+
+	// Assert
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errRead)
+}
+
+func buildAccountTransaction(id int, account string, month time.Month, value string) model.Transaction {
+	transaction := buildTransaction(id, month, value)
+	transaction.Account = account
+
+	return transaction
+}
@@ -0,0 +1,175 @@
+package accountsummary
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"stori/model"
+)
+
+const (
+	DefaultMaxAttempts = 5
+	DefaultBaseDelay   = time.Minute
+	DefaultMaxDelay    = time.Hour
+	DefaultClaimSize   = 50
+	DefaultLease       = 5 * time.Minute
+)
+
+type (
+	// DispatcherConfig tells how the emails of the outbox are sent. Zero values get the defaults above.
+	DispatcherConfig struct {
+		Outbox      Outbox
+		EmailSender EmailSender
+		// MaxAttempts is the number of sends an email gets before it is marked as failed.
+		MaxAttempts int
+		// BaseDelay is the wait before the first retry. It doubles on every attempt, up to MaxDelay.
+		BaseDelay time.Duration
+		MaxDelay  time.Duration
+		// ClaimSize is the number of emails claimed from the outbox at a time.
+		ClaimSize int
+		// Lease is how long claimed emails are hidden from other dispatchers. It must exceed the time it takes to
+		// send ClaimSize emails.
+		Lease time.Duration
+	}
+
+	// Dispatcher sends the pending emails of the outbox, retrying the failed ones with exponential backoff.
+	Dispatcher struct {
+		DispatcherConfig
+	}
+
+	// DispatchReport counts what happened to the emails dispatched. Errors holds the error of every failed send,
+	// whether it is retried or not.
+	DispatchReport struct {
+		Sent    int
+		Retried int
+		Failed  int
+		Errors  []error
+	}
+)
+
+func NewDispatcher(config DispatcherConfig) Dispatcher {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = DefaultBaseDelay
+	}
+
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = DefaultMaxDelay
+	}
+
+	if config.ClaimSize <= 0 {
+		config.ClaimSize = DefaultClaimSize
+	}
+
+	if config.Lease <= 0 {
+		config.Lease = DefaultLease
+	}
+
+	return Dispatcher{DispatcherConfig: config}
+}
+
+// Dispatch sends every email of the outbox that is due, until none is left. A failed send is not an error: it is
+// retried later, or marked as failed once it runs out of attempts. Only errors of the outbox and the cancellation of
+// the context stop the dispatch.
+func (dispatcher Dispatcher) Dispatch(ctx context.Context) (DispatchReport, error) {
+	fail := func(err error) (DispatchReport, error) {
+		return DispatchReport{}, fmt.Errorf("app: Dispatcher: Dispatch: %w", err)
+	}
+
+	var report DispatchReport
+	for {
+		emails, err := dispatcher.Outbox.Claim(ctx, dispatcher.ClaimSize, dispatcher.Lease)
+		if err != nil {
+			return fail(err)
+		}
+
+		if len(emails) == 0 {
+			return report, nil
+		}
+
+		if err = dispatcher.send(ctx, emails, &report); err != nil {
+			return fail(err)
+		}
+	}
+}
+
+// DispatchSummary sends the pending email of the summary only, e.g. the one a run just queued, leaving the rest of
+// the outbox to Dispatch. Failed sends are handled the same way.
+func (dispatcher Dispatcher) DispatchSummary(ctx context.Context, summaryID int64) (DispatchReport, error) {
+	fail := func(err error) (DispatchReport, error) {
+		return DispatchReport{}, fmt.Errorf("app: Dispatcher: DispatchSummary %d: %w", summaryID, err)
+	}
+
+	emails, err := dispatcher.Outbox.ClaimSummary(ctx, summaryID, dispatcher.Lease)
+	if err != nil {
+		return fail(err)
+	}
+
+	var report DispatchReport
+	if err = dispatcher.send(ctx, emails, &report); err != nil {
+		return fail(err)
+	}
+
+	return report, nil
+}
+
+// send sends the claimed emails and records the outcome of each one in the outbox and in the report.
+func (dispatcher Dispatcher) send(ctx context.Context, emails []model.OutboxEmail, report *DispatchReport) error {
+	for _, email := range emails {
+		errSend := dispatcher.EmailSender.Send(ctx, email.Summary)
+		if errSend == nil {
+			if err := dispatcher.Outbox.MarkSent(ctx, email.ID); err != nil {
+				return err
+			}
+
+			report.Sent++
+
+			continue
+		}
+
+		// A send interrupted by the context is not an attempt: the email is claimed again once the lease expires.
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		report.Errors = append(report.Errors, errSend)
+
+		attempts := email.Attempts + 1
+		if attempts >= dispatcher.MaxAttempts {
+			if err := dispatcher.Outbox.MarkFailed(ctx, email.ID, attempts, errSend.Error()); err != nil {
+				return err
+			}
+
+			report.Failed++
+
+			continue
+		}
+
+		nextAttemptAt := time.Now().UTC().Add(dispatcher.backoff(attempts))
+		if err := dispatcher.Outbox.MarkRetry(ctx, email.ID, attempts, errSend.Error(), nextAttemptAt); err != nil {
+			return err
+		}
+
+		report.Retried++
+	}
+
+	return nil
+}
+
+// backoff is the wait after the failed attempt: BaseDelay, then doubled on every attempt up to MaxDelay.
+func (dispatcher Dispatcher) backoff(attempts int) time.Duration {
+	delay := dispatcher.BaseDelay
+	for range attempts - 1 {
+		if delay >= dispatcher.MaxDelay/2 {
+			return dispatcher.MaxDelay
+		}
+
+		delay *= 2
+	}
+
+	return min(delay, dispatcher.MaxDelay)
+}
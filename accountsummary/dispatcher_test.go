@@ -0,0 +1,190 @@
+package accountsummary_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	accsum "stori/accountsummary"
+	mocks "stori/mocks/stori/accountsummary"
+	"stori/model"
+)
+
+var errSMTP = errors.New("smtp unavailable")
+
+func TestDispatch_WhenSendSucceeds_MarkedSent(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	summary := model.AccountSummary{Email: "john.doe@stori.com"}
+	outboxMock := mocks.NewMockOutbox(t)
+	emailSenderMock := mocks.NewMockEmailSender(t)
+
+	outboxMock.EXPECT().Claim(mock.Anything, accsum.DefaultClaimSize, accsum.DefaultLease).
+		Return([]model.OutboxEmail{{ID: 7, Summary: summary}}, nil).Once()
+	outboxMock.EXPECT().Claim(mock.Anything, accsum.DefaultClaimSize, accsum.DefaultLease).Return(nil, nil).Once()
+	emailSenderMock.EXPECT().Send(mock.Anything, summary).Return(nil)
+	outboxMock.EXPECT().MarkSent(mock.Anything, int64(7)).Return(nil)
+
+	sut := accsum.NewDispatcher(accsum.DispatcherConfig{Outbox: outboxMock, EmailSender: emailSenderMock})
+
+	// Act
+	report, err := sut.Dispatch(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, accsum.DispatchReport{Sent: 1}, report)
+}
+
+func TestDispatch_WhenSendFails_RetriedWithBackoff(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		attempts      int
+		expectedDelay time.Duration
+	}{
+		{name: "first failure waits the base delay", attempts: 0, expectedDelay: time.Minute},
+		{name: "delay doubles on every attempt", attempts: 2, expectedDelay: 4 * time.Minute},
+		{name: "delay is capped", attempts: 8, expectedDelay: 10 * time.Minute},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			outboxMock := mocks.NewMockOutbox(t)
+			emailSenderMock := mocks.NewMockEmailSender(t)
+
+			outboxMock.EXPECT().Claim(mock.Anything, mock.Anything, mock.Anything).
+				Return([]model.OutboxEmail{{ID: 7, Attempts: tc.attempts}}, nil).Once()
+			outboxMock.EXPECT().Claim(mock.Anything, mock.Anything, mock.Anything).Return(nil, nil).Once()
+			emailSenderMock.EXPECT().Send(mock.Anything, mock.Anything).Return(errSMTP)
+
+			var nextAttemptAt time.Time
+			outboxMock.EXPECT().MarkRetry(mock.Anything, int64(7), tc.attempts+1, errSMTP.Error(), mock.Anything).
+				Run(func(_ context.Context, _ int64, _ int, _ string, at time.Time) { nextAttemptAt = at }).Return(nil)
+
+			sut := accsum.NewDispatcher(accsum.DispatcherConfig{
+				Outbox:      outboxMock,
+				EmailSender: emailSenderMock,
+				MaxAttempts: 10,
+				BaseDelay:   time.Minute,
+				MaxDelay:    10 * time.Minute,
+			})
+
+			// Act
+			before := time.Now()
+			report, err := sut.Dispatch(context.Background())
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, accsum.DispatchReport{Retried: 1, Errors: []error{errSMTP}}, report)
+			assert.WithinRange(t, nextAttemptAt, before.Add(tc.expectedDelay), time.Now().Add(tc.expectedDelay))
+		})
+	}
+}
+
+func TestDispatch_WhenOutOfAttempts_MarkedFailed(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	outboxMock := mocks.NewMockOutbox(t)
+	emailSenderMock := mocks.NewMockEmailSender(t)
+
+	outboxMock.EXPECT().Claim(mock.Anything, mock.Anything, mock.Anything).
+		Return([]model.OutboxEmail{{ID: 7, Attempts: 2}}, nil).Once()
+	outboxMock.EXPECT().Claim(mock.Anything, mock.Anything, mock.Anything).Return(nil, nil).Once()
+	emailSenderMock.EXPECT().Send(mock.Anything, mock.Anything).Return(errSMTP)
+	outboxMock.EXPECT().MarkFailed(mock.Anything, int64(7), 3, errSMTP.Error()).Return(nil)
+
+	sut := accsum.NewDispatcher(accsum.DispatcherConfig{
+		Outbox:      outboxMock,
+		EmailSender: emailSenderMock,
+		MaxAttempts: 3,
+	})
+
+	// Act
+	report, err := sut.Dispatch(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, accsum.DispatchReport{Failed: 1, Errors: []error{errSMTP}}, report)
+}
+
+func TestDispatchSummary_OnlyEmailOfSummarySent(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	summary := model.AccountSummary{Email: "john.doe@stori.com"}
+	outboxMock := mocks.NewMockOutbox(t)
+	emailSenderMock := mocks.NewMockEmailSender(t)
+
+	outboxMock.EXPECT().ClaimSummary(mock.Anything, int64(3), accsum.DefaultLease).
+		Return([]model.OutboxEmail{{ID: 7, Summary: summary}}, nil).Once()
+	emailSenderMock.EXPECT().Send(mock.Anything, summary).Return(nil)
+	outboxMock.EXPECT().MarkSent(mock.Anything, int64(7)).Return(nil)
+
+	sut := accsum.NewDispatcher(accsum.DispatcherConfig{Outbox: outboxMock, EmailSender: emailSenderMock})
+
+	// Act
+	report, err := sut.DispatchSummary(context.Background(), 3)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, accsum.DispatchReport{Sent: 1}, report)
+}
+
+func TestDispatch_WhenContextCancelledWhileSending_StopsWithoutCountingAttempt(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	outboxMock := mocks.NewMockOutbox(t)
+	emailSenderMock := mocks.NewMockEmailSender(t)
+
+	outboxMock.EXPECT().Claim(mock.Anything, mock.Anything, mock.Anything).
+		Return([]model.OutboxEmail{{ID: 7}, {ID: 8}}, nil).Once()
+	emailSenderMock.EXPECT().Send(mock.Anything, mock.Anything).
+		RunAndReturn(func(ctx context.Context, _ model.AccountSummary) error {
+			cancel()
+			return ctx.Err()
+		}).Once()
+
+	sut := accsum.NewDispatcher(accsum.DispatcherConfig{Outbox: outboxMock, EmailSender: emailSenderMock})
+
+	// Act
+	_, err := sut.Dispatch(ctx)
+
+	// Assert
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestDispatch_WhenClaimFails_Error(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	errClaim := errors.New("connection refused")
+	outboxMock := mocks.NewMockOutbox(t)
+	outboxMock.EXPECT().Claim(mock.Anything, mock.Anything, mock.Anything).Return(nil, errClaim)
+
+	sut := accsum.NewDispatcher(accsum.DispatcherConfig{
+		Outbox:      outboxMock,
+		EmailSender: mocks.NewMockEmailSender(t),
+	})
+
+	// Act
+	_, err := sut.Dispatch(context.Background())
+
+	// Assert
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errClaim)
+}
@@ -0,0 +1,40 @@
+package accountsummary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrAlreadyProcessed is returned when the source was already summarized for the email and OnDuplicate is
+// RejectDuplicates.
+var ErrAlreadyProcessed = errors.New("already processed")
+
+// DuplicatePolicy decides what happens when a source is processed again for the same email.
+type DuplicatePolicy int
+
+const (
+	// SkipDuplicates does nothing: the summary is neither persisted nor sent again.
+	SkipDuplicates DuplicatePolicy = iota
+	// RejectDuplicates fails with ErrAlreadyProcessed.
+	RejectDuplicates
+)
+
+// checkDuplicate fails with ErrAlreadyProcessed when a completed execution already summarized the source of the
+// execution for the email and account. Sources the reader cannot fingerprint are never duplicates.
+func (app App) checkDuplicate(ctx context.Context, email, account string, execution Execution) error {
+	if app.Force || execution.Checksum == "" || app.Repository == nil {
+		return nil
+	}
+
+	processed, err := app.Repository.WasProcessed(ctx, email, account, execution.Checksum)
+	if err != nil {
+		return fmt.Errorf("app: App: checkDuplicate: %w", err)
+	}
+
+	if processed {
+		return fmt.Errorf("app: App: checkDuplicate %s: %w", execution.Checksum, ErrAlreadyProcessed)
+	}
+
+	return nil
+}
@@ -1,11 +1,20 @@
 package accountsummary
 
 import (
+	"iter"
+	"time"
+
 	"stori/model"
 )
 
+// Execution is a run of the app over a source, with the summary it produced.
+// Checksum is empty when the reader cannot fingerprint its source.
+// QueueEmail queues the email of the summary in the Outbox along with it, when the app sends its emails from there.
 type Execution struct {
 	AccountSummary model.AccountSummary
-	Transactions   []model.Transaction
+	Transactions   iter.Seq2[model.Transaction, error]
 	FilePath       string
+	Checksum       string
+	StartedAt      time.Time
+	QueueEmail     bool
 }
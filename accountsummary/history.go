@@ -0,0 +1,25 @@
+package accountsummary
+
+import "time"
+
+// DefaultPageSize is the number of summaries listed when SummaryFilter.Limit is not set.
+const DefaultPageSize = 50
+
+// SummaryFilter selects the stored summaries of an email, newest first.
+// CreatedFrom and CreatedTo bound the time of the execution, inclusive, and are ignored when zero.
+type SummaryFilter struct {
+	Email       string
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	Limit       int
+	Offset      int
+}
+
+// PageSize is the Limit of the filter, or DefaultPageSize when not set.
+func (filter SummaryFilter) PageSize() int {
+	if filter.Limit <= 0 {
+		return DefaultPageSize
+	}
+
+	return filter.Limit
+}
@@ -1,15 +1,61 @@
 package accountsummary
 
-import "stori/model"
+import (
+	"context"
+	"iter"
+	"time"
+
+	"stori/model"
+)
+
+// Every port takes the context of the run, so that a deadline or a cancellation stops the work in flight.
 
 type TransactionsReader interface {
-	ReadTransactions() ([]model.Transaction, error)
+	// StreamTransactions yields an error and stops when the context is done.
+	StreamTransactions(ctx context.Context) iter.Seq2[model.Transaction, error]
+}
+
+// Checksummer is implemented by the readers that can fingerprint their source.
+type Checksummer interface {
+	Checksum(ctx context.Context) (string, error)
 }
 
 type EmailSender interface {
-	Send(summary model.AccountSummary) error
+	Send(ctx context.Context, summary model.AccountSummary) error
 }
 
 type Repository interface {
-	Create(execution Execution) error
+	// Create stores the execution and returns the id of its summary.
+	Create(ctx context.Context, execution Execution) (int64, error)
+	// WasProcessed tells whether a completed execution already summarized the source with the checksum for the
+	// email and account. Account is empty outside batch runs.
+	WasProcessed(ctx context.Context, email, account, checksum string) (bool, error)
+}
+
+// Outbox holds the emails of the stored summaries until they are sent. The repository queues them along with the
+// summaries, so that only committed executions are emailed.
+type Outbox interface {
+	// Claim returns up to limit pending emails that are due, oldest first, and hides them from other claims for the
+	// lease, so that concurrent dispatchers do not send them twice.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxEmail, error)
+	// ClaimSummary is Claim for the email of a summary only. It returns no email when it was already sent or is
+	// claimed by another dispatcher.
+	ClaimSummary(ctx context.Context, summaryID int64, lease time.Duration) ([]model.OutboxEmail, error)
+	MarkSent(ctx context.Context, id int64) error
+	// MarkRetry records a failed send, to be retried at nextAttemptAt.
+	MarkRetry(ctx context.Context, id int64, attempts int, lastErr string, nextAttemptAt time.Time) error
+	// MarkFailed records a failed send that is not retried anymore.
+	MarkFailed(ctx context.Context, id int64, attempts int, lastErr string) error
+}
+
+// RecipientDirectory finds the email address the summary of an account is sent to.
+type RecipientDirectory interface {
+	Email(account string) (string, error)
+}
+
+// History reads back the results of past executions, so that other tools can build on them.
+type History interface {
+	GetSummary(ctx context.Context, id int64) (model.StoredSummary, error)
+	ListSummaries(ctx context.Context, filter SummaryFilter) ([]model.StoredSummary, error)
+	ListTransactions(ctx context.Context, executionID string) ([]model.Transaction, error)
 }
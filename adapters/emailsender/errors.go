@@ -4,3 +4,6 @@ import "errors"
 
 var ErrWrongFormAddress = errors.New("wrong form address")
 var ErrWrongTargetAddress = errors.New("wrong target address")
+var ErrUnknownLocale = errors.New("unknown locale")
+var ErrPreviewWrite = errors.New("error writing email preview")
+var ErrSendEmail = errors.New("error sending email")
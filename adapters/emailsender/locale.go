@@ -0,0 +1,131 @@
+package emailsender
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/govalues/decimal"
+
+	"stori/model"
+)
+
+const DefaultLocale = "en-US"
+
+// locale describes how a summary is written for the users of a language and region.
+// Its templates are in templates/<name>/summary.html and templates/<name>/summary.txt.
+type locale struct {
+	name              string
+	subject           string
+	currencyFormat    string
+	groupSeparator    string
+	decimalSeparator  string
+	months            [12]string
+	dateFormat        string
+	yearMonthFormat   string
+	balanceDateFormat string
+}
+
+var locales = map[string]locale{ //nolint:gochecknoglobals // Read only
+	"en-US": {
+		name:              "en-US",
+		subject:           "Stori - Account Summary",
+		currencyFormat:    "$ %s",
+		groupSeparator:    ",",
+		decimalSeparator:  ".",
+		months:            englishMonths(),
+		dateFormat:        "%[2]s %[1]d, %[3]d",
+		yearMonthFormat:   "%s %d",
+		balanceDateFormat: "%s on %s",
+	},
+	"es-MX": {
+		name:             "es-MX",
+		subject:          "Stori - Resumen de cuenta",
+		currencyFormat:   "$ %s",
+		groupSeparator:   ",",
+		decimalSeparator: ".",
+		months: [12]string{
+			"enero", "febrero", "marzo", "abril", "mayo", "junio",
+			"julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre",
+		},
+		dateFormat:        "%d de %s de %d",
+		yearMonthFormat:   "%s de %d",
+		balanceDateFormat: "%s el %s",
+	},
+}
+
+// localeFor returns the locale of the given name, e.g. "es-MX". An empty name is the DefaultLocale.
+func localeFor(name string) (locale, error) {
+	if name == "" {
+		name = DefaultLocale
+	}
+
+	loc, ok := locales[name]
+	if !ok {
+		return locale{}, fmt.Errorf("emailsender: localeFor %q: %w", name, ErrUnknownLocale)
+	}
+
+	return loc, nil
+}
+
+func englishMonths() [12]string {
+	var months [12]string
+	for month := time.January; month <= time.December; month++ {
+		months[month-1] = month.String()
+	}
+
+	return months
+}
+
+func (loc locale) month(month time.Month) string {
+	return loc.months[month-1]
+}
+
+func (loc locale) date(date time.Time) string {
+	return fmt.Sprintf(loc.dateFormat, date.Day(), loc.month(date.Month()), date.Year())
+}
+
+func (loc locale) yearMonth(yearMonth model.YearMonth) string {
+	return fmt.Sprintf(loc.yearMonthFormat, loc.month(yearMonth.Month), yearMonth.Year)
+}
+
+func (loc locale) amount(amount decimal.Decimal) string {
+	return fmt.Sprintf(loc.currencyFormat, loc.number(amount.Round(numberOfDecimals).Pad(numberOfDecimals).String()))
+}
+
+func (loc locale) balanceOn(amount decimal.Decimal, date time.Time) string {
+	return fmt.Sprintf(loc.balanceDateFormat, loc.amount(amount), loc.date(date))
+}
+
+// number groups the integer digits of decimalStr by three and uses the separators of the locale.
+func (loc locale) number(decimalStr string) string {
+	buf := &bytes.Buffer{}
+
+	// The sign is written apart so it is not counted as a digit when grouping by three.
+	if strings.HasPrefix(decimalStr, "-") {
+		buf.WriteString("-")
+		decimalStr = decimalStr[1:]
+	}
+
+	parts := strings.Split(decimalStr, ".")
+	integer := parts[0]
+
+	first := len(integer) % byThree
+	if first == 0 {
+		first = byThree
+	}
+	buf.WriteString(integer[:first])
+
+	for pos := first; pos < len(integer); pos += byThree {
+		buf.WriteString(loc.groupSeparator)
+		buf.WriteString(integer[pos : pos+byThree])
+	}
+
+	if len(parts) > 1 {
+		buf.WriteString(loc.decimalSeparator)
+		buf.WriteString(parts[1])
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,73 @@
+package emailsender
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"stori/model"
+)
+
+const previewFromAddress = "preview@localhost"
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9@._-]+`) //nolint:gochecknoglobals // Read only
+
+// Preview writes the summary emails to a directory instead of sending them: the whole message as a .eml file and
+// its HTML body as a .html file, both named after the account and the recipient. The message is the same one
+// Sender builds, so no SMTP server is needed to check it.
+type Preview struct {
+	sender Sender
+	dir    string
+}
+
+// NewPreview renders emails with the locale and the sender address of the config. Without a sender address, a
+// placeholder is used. The SMTP settings are ignored.
+func NewPreview(config Config, dir string) Preview {
+	if config.Username == "" {
+		config.Username = previewFromAddress
+	}
+
+	return Preview{sender: New(config), dir: dir}
+}
+
+func (preview Preview) Send(ctx context.Context, summary model.AccountSummary) error {
+	fail := func(err error) error {
+		return fmt.Errorf("emailsender: Preview: Send: %w", err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fail(err)
+	}
+
+	msg, body, err := preview.sender.buildMessage(summary)
+	if err != nil {
+		return fail(err)
+	}
+
+	if err = os.MkdirAll(preview.dir, 0o755); err != nil {
+		return fail(fmt.Errorf("%w: %w", ErrPreviewWrite, err))
+	}
+
+	name := filepath.Join(preview.dir, previewFileName(summary))
+
+	if err = msg.WriteToFile(name + ".eml"); err != nil {
+		return fail(fmt.Errorf("%w: %w", ErrPreviewWrite, err))
+	}
+
+	if err = os.WriteFile(name+".html", []byte(body.html), 0o644); err != nil {
+		return fail(fmt.Errorf("%w: %w", ErrPreviewWrite, err))
+	}
+
+	return nil
+}
+
+func previewFileName(summary model.AccountSummary) string {
+	name := summary.Email
+	if summary.Account != "" {
+		name = summary.Account + "_" + name
+	}
+
+	return unsafeFileNameChars.ReplaceAllString(name, "_")
+}
@@ -0,0 +1,65 @@
+package emailsender_test
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stori/adapters/emailsender"
+	"stori/test"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the rendered emails")
+
+func TestPreviewSend_RenderedEmailMatchesGoldenFile(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		locale string
+		golden string
+	}{
+		{name: "english", locale: "en-US", golden: "testdata/summary_en-US.golden.html"},
+		{name: "spanish from Mexico", locale: "es-MX", golden: "testdata/summary_es-MX.golden.html"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			dir := t.TempDir()
+			summary := buildSummary()
+			summary.Account = "ACC/1"
+			sut := emailsender.NewPreview(emailsender.Config{Locale: tc.locale}, dir)
+
+			// Act
+			err := sut.Send(context.Background(), summary)
+
+			// Assert
+			require.NoError(t, err)
+
+			eml, err := os.ReadFile(filepath.Join(dir, "ACC_1_john.doe@stori.com.eml"))
+			require.NoError(t, err)
+			assert.Contains(t, string(eml), "To: <john.doe@stori.com>")
+			assert.Contains(t, string(eml), "multipart/alternative")
+
+			html, err := os.ReadFile(filepath.Join(dir, "ACC_1_john.doe@stori.com.html"))
+			require.NoError(t, err)
+
+			if *update {
+				require.NoError(t, os.WriteFile(tc.golden, html, 0o644))
+			}
+
+			golden, err := os.ReadFile(tc.golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(golden), string(html))
+		})
+	}
+}
@@ -1,23 +1,20 @@
 package emailsender
 
 import (
-	"bytes"
+	"context"
 	"embed"
 	"fmt"
-	"html/template"
+	htmltemplate "html/template"
 	"sort"
 	"strings"
-	"time"
+	texttemplate "text/template"
 
-	"github.com/govalues/decimal"
 	"github.com/wneessen/go-mail"
 
 	"stori/model"
 )
 
 const (
-	subject = "Stori - Account Summary"
-
 	numberOfDecimals = 2
 	byThree          = 3
 )
@@ -35,6 +32,8 @@ type (
 		Port     int
 		Username string
 		Password string
+		// Locale of the summaries, e.g. "es-MX". Defaults to DefaultLocale.
+		Locale string
 	}
 
 	templateData struct {
@@ -42,24 +41,48 @@ type (
 		MonthsData          []MonthsData
 		AverageDebitAmount  string
 		AverageCreditAmount string
+		MinimumBalance      string
+		MaximumBalance      string
+		DailyBalances       []DailyBalanceData
+		MonthlyBreakdown    []PeriodData
 	}
 
 	MonthsData struct {
 		Month string
 		Count int
 	}
+
+	DailyBalanceData struct {
+		Date    string
+		Balance string
+	}
+
+	renderedBody struct {
+		text string
+		html string
+	}
+
+	PeriodData struct {
+		Month               string
+		TotalCredit         string
+		TotalDebit          string
+		NetFlow             string
+		AverageCreditAmount string
+		AverageDebitAmount  string
+	}
 )
 
 func New(config Config) Sender {
 	return Sender{Config: config}
 }
 
-func (sender Sender) Send(summary model.AccountSummary) error {
+// Send gives up on the SMTP server when the context is done.
+func (sender Sender) Send(ctx context.Context, summary model.AccountSummary) error {
 	fail := func(err error) error {
 		return fmt.Errorf("emailsender: sender: Send: %w", err)
 	}
 
-	message, err := sender.buildSummaryMessage(summary)
+	message, err := sender.BuildMessage(summary)
 	if err != nil {
 		return fail(err)
 	}
@@ -67,34 +90,55 @@ func (sender Sender) Send(summary model.AccountSummary) error {
 	client, err := mail.NewClient(sender.Host, mail.WithPort(sender.Port), mail.WithSMTPAuth(mail.SMTPAuthPlain),
 		mail.WithUsername(sender.Username), mail.WithPassword(sender.Password))
 	if err != nil {
-		return fail(fmt.Errorf("failed to create mail client: %w", err))
+		return fail(fmt.Errorf("%w: failed to create mail client: %w", ErrSendEmail, err))
 	}
 
-	if errDial := client.DialAndSend(message); errDial != nil {
-		return fail(fmt.Errorf("failed to send mail: %w", errDial))
+	if errDial := client.DialAndSendWithContext(ctx, message); errDial != nil {
+		return fail(fmt.Errorf("%w: %w", ErrSendEmail, errDial))
 	}
 
 	return nil
 }
 
-func (sender Sender) buildSummaryMessage(summary model.AccountSummary) (*mail.Msg, error) {
-	fail := func(err error) (*mail.Msg, error) {
-		return nil, fmt.Errorf("emailsender: sender: buildSummaryMessage: %w", err)
+// BuildMessage builds the summary email without sending it.
+func (sender Sender) BuildMessage(summary model.AccountSummary) (*mail.Msg, error) {
+	msg, _, err := sender.buildMessage(summary)
+	if err != nil {
+		return nil, fmt.Errorf("emailsender: sender: BuildMessage: %w", err)
+	}
+
+	return msg, nil
+}
+
+// buildMessage also returns the rendered body, for senders that use it apart from the message.
+func (sender Sender) buildMessage(summary model.AccountSummary) (*mail.Msg, renderedBody, error) {
+	fail := func(err error) (*mail.Msg, renderedBody, error) {
+		return nil, renderedBody{}, fmt.Errorf("emailsender: sender: buildMessage: %w", err)
+	}
+
+	loc, err := localeFor(sender.Locale)
+	if err != nil {
+		return fail(err)
 	}
 
 	msg := mail.NewMsg()
-	if err := sender.addMetadata(msg, summary.Email); err != nil {
+	if err = sender.addMetadata(msg, summary.Email, loc); err != nil {
 		return fail(err)
 	}
 
-	if err := sender.addBody(msg, summary); err != nil {
+	body, err := renderBody(summary, loc)
+	if err != nil {
 		return fail(err)
 	}
 
-	return msg, nil
+	// The plain text part comes first, so that clients rejecting HTML-only mail still get it.
+	msg.SetBodyString(mail.TypeTextPlain, body.text)
+	msg.AddAlternativeString(mail.TypeTextHTML, body.html)
+
+	return msg, body, nil
 }
 
-func (sender Sender) addMetadata(msg *mail.Msg, receiverAddress string) error {
+func (sender Sender) addMetadata(msg *mail.Msg, receiverAddress string, loc locale) error {
 	fail := func(err error) error {
 		return fmt.Errorf("emailsender: sender: addMetadata: %w", err)
 	}
@@ -107,87 +151,104 @@ func (sender Sender) addMetadata(msg *mail.Msg, receiverAddress string) error {
 		return fail(ErrWrongTargetAddress)
 	}
 
-	msg.Subject(subject)
+	msg.Subject(loc.subject)
 
 	return nil
 }
 
-func (sender Sender) addBody(msg *mail.Msg, summary model.AccountSummary) error {
-	fail := func(err error) error {
-		return fmt.Errorf("emailsender: sender: addBody: %w", err)
+func renderBody(summary model.AccountSummary, loc locale) (renderedBody, error) {
+	fail := func(err error) (renderedBody, error) {
+		return renderedBody{}, fmt.Errorf("emailsender: renderBody: %w", err)
+	}
+
+	textTempl, err := texttemplate.ParseFS(templates, fmt.Sprintf("templates/%s/summary.txt", loc.name))
+	if err != nil {
+		return fail(err)
 	}
 
-	templ, err := template.ParseFS(templates, "templates/summary.html")
+	htmlTempl, err := htmltemplate.ParseFS(templates, fmt.Sprintf("templates/%s/summary.html", loc.name))
 	if err != nil {
 		return fail(err)
 	}
 
-	if err = msg.SetBodyHTMLTemplate(templ, buildTemplateData(summary)); err != nil {
+	data := buildTemplateData(summary, loc)
+	text, html := &strings.Builder{}, &strings.Builder{}
+
+	if err = textTempl.Execute(text, data); err != nil {
 		return fail(err)
 	}
 
-	return nil
+	if err = htmlTempl.Execute(html, data); err != nil {
+		return fail(err)
+	}
+
+	return renderedBody{text: text.String(), html: html.String()}, nil
 }
 
-func buildTemplateData(summary model.AccountSummary) templateData {
-	totalBalance := printableAmount(summary.TotalBalance)
-	avgDebit := printableAmount(summary.AverageDebitAmount)
-	avgCredit := printableAmount(summary.AverageCreditAmount)
-	monthsData := buildMonthData(summary)
+func buildTemplateData(summary model.AccountSummary, loc locale) templateData {
+	totalBalance := loc.amount(summary.TotalBalance)
+	avgDebit := loc.amount(summary.AverageDebitAmount)
+	avgCredit := loc.amount(summary.AverageCreditAmount)
+	monthsData := buildMonthData(summary, loc)
+	minBalance := loc.balanceOn(summary.MinimumBalance, summary.MinimumBalanceDate)
+	maxBalance := loc.balanceOn(summary.MaximumBalance, summary.MaximumBalanceDate)
+	dailyBalances := buildDailyBalanceData(summary, loc)
+	monthlyBreakdown := buildPeriodData(summary, loc)
 
 	return templateData{
 		TotalBalance:        totalBalance,
 		MonthsData:          monthsData,
 		AverageDebitAmount:  avgDebit,
 		AverageCreditAmount: avgCredit,
+		MinimumBalance:      minBalance,
+		MaximumBalance:      maxBalance,
+		DailyBalances:       dailyBalances,
+		MonthlyBreakdown:    monthlyBreakdown,
 	}
 }
 
-func buildMonthData(summary model.AccountSummary) []MonthsData {
-	keys := make([]time.Month, 0, len(summary.TransactionsPerMonth))
+func buildMonthData(summary model.AccountSummary, loc locale) []MonthsData {
+	keys := make([]model.YearMonth, 0, len(summary.TransactionsPerMonth))
 
 	for k := range summary.TransactionsPerMonth {
 		keys = append(keys, k)
 	}
 	sort.Slice(keys, func(i, j int) bool {
-		return keys[i] < keys[j]
+		return keys[i].Before(keys[j])
 	})
 
 	var monthsData []MonthsData
 	for _, k := range keys {
-		monthsData = append(monthsData, MonthsData{Month: k.String(), Count: summary.TransactionsPerMonth[k]})
+		monthsData = append(monthsData, MonthsData{Month: loc.yearMonth(k), Count: summary.TransactionsPerMonth[k]})
 	}
 
 	return monthsData
 }
 
-func printableAmount(amount decimal.Decimal) string {
-	return fmt.Sprintf("$ %s", addCommas(amount.Round(numberOfDecimals).Pad(numberOfDecimals).String()))
-}
-
-func addCommas(decimalStr string) string {
-	buf := &bytes.Buffer{}
-	comma := []byte{','}
-
-	parts := strings.Split(decimalStr, ".")
-	pos := 0
-
-	if len(parts[0])%byThree != 0 {
-		pos += len(parts[0]) % byThree
-		buf.WriteString(parts[0][:pos])
-		buf.Write(comma)
+func buildDailyBalanceData(summary model.AccountSummary, loc locale) []DailyBalanceData {
+	dailyBalances := make([]DailyBalanceData, 0, len(summary.DailyBalances))
+	for _, balance := range summary.DailyBalances {
+		dailyBalances = append(dailyBalances, DailyBalanceData{
+			Date:    loc.date(balance.Date),
+			Balance: loc.amount(balance.Balance),
+		})
 	}
 
-	for ; pos < len(parts[0]); pos += byThree {
-		buf.WriteString(parts[0][pos : pos+byThree])
-		buf.Write(comma)
-	}
-	buf.Truncate(buf.Len() - 1)
+	return dailyBalances
+}
 
-	if len(parts) > 1 {
-		buf.Write([]byte{'.'})
-		buf.WriteString(parts[1])
+func buildPeriodData(summary model.AccountSummary, loc locale) []PeriodData {
+	periodsData := make([]PeriodData, 0, len(summary.MonthlyBreakdown))
+	for _, breakdown := range summary.MonthlyBreakdown {
+		periodsData = append(periodsData, PeriodData{
+			Month:               loc.yearMonth(breakdown.Period),
+			TotalCredit:         loc.amount(breakdown.TotalCredit),
+			TotalDebit:          loc.amount(breakdown.TotalDebit),
+			NetFlow:             loc.amount(breakdown.NetFlow),
+			AverageCreditAmount: loc.amount(breakdown.AverageCreditAmount),
+			AverageDebitAmount:  loc.amount(breakdown.AverageDebitAmount),
+		})
 	}
 
-	return buf.String()
+	return periodsData
 }
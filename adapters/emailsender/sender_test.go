@@ -0,0 +1,150 @@
+package emailsender_test
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"testing"
+	"time"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stori/adapters/emailsender"
+	"stori/model"
+)
+
+func TestBuildMessage_WhenLocale_TextAndHTMLParts(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		locale  string
+		subject string
+		text    []string
+		html    []string
+	}{
+		{
+			name:    "default locale",
+			locale:  "",
+			subject: "Stori - Account Summary",
+			text: []string{
+				"Total balance: $ -1,234,567.50",
+				"Minimum balance: $ -1,234,567.50 on July 15, 2024",
+				"- July 2024: 2",
+			},
+			html: []string{"Total Balance:", "$ -1,234,567.50", "July 2024"},
+		},
+		{
+			name:    "spanish from Mexico",
+			locale:  "es-MX",
+			subject: "Stori - Resumen de cuenta",
+			text: []string{
+				"Saldo total: $ -1,234,567.50",
+				"Saldo mínimo: $ -1,234,567.50 el 15 de julio de 2024",
+				"- julio de 2024: 2",
+			},
+			html: []string{"Saldo total:", "$ -1,234,567.50", "julio de 2024"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			sut := emailsender.New(emailsender.Config{Username: "stori@stori.com", Locale: tc.locale})
+
+			// Act
+			msg, err := sut.BuildMessage(buildSummary())
+
+			// Assert
+			require.NoError(t, err)
+
+			subject, parts := readMessage(t, msg)
+			assert.Equal(t, tc.subject, subject)
+			require.Contains(t, parts, "text/plain")
+			require.Contains(t, parts, "text/html")
+
+			for _, expected := range tc.text {
+				assert.Contains(t, parts["text/plain"], expected)
+			}
+
+			for _, expected := range tc.html {
+				assert.Contains(t, parts["text/html"], expected)
+			}
+		})
+	}
+}
+
+func TestBuildMessage_WhenUnknownLocale_Error(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	sut := emailsender.New(emailsender.Config{Username: "stori@stori.com", Locale: "xx-XX"})
+
+	// Act
+	_, err := sut.BuildMessage(buildSummary())
+
+	// Assert
+	require.Error(t, err)
+	assert.ErrorIs(t, err, emailsender.ErrUnknownLocale)
+}
+
+func buildSummary() model.AccountSummary {
+	july := time.Date(2024, time.July, 15, 0, 0, 0, 0, time.UTC)
+
+	return model.AccountSummary{
+		Email:                "john.doe@stori.com",
+		TotalBalance:         decimal.MustParse("-1234567.5"),
+		TransactionsPerMonth: map[model.YearMonth]int{{Year: 2024, Month: time.July}: 2},
+		MinimumBalance:       decimal.MustParse("-1234567.5"),
+		MinimumBalanceDate:   july,
+		MaximumBalance:       decimal.MustParse("10"),
+		MaximumBalanceDate:   july,
+	}
+}
+
+// readMessage returns the decoded subject and the decoded body of each part of the message, by content type.
+func readMessage(t *testing.T, msg interface {
+	WriteTo(io.Writer) (int64, error)
+}) (string, map[string]string) {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	_, err := msg.WriteTo(buf)
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(buf)
+	require.NoError(t, err)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	parts := make(map[string]string)
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	for {
+		part, errPart := reader.NextPart()
+		if errPart == io.EOF {
+			break
+		}
+		require.NoError(t, errPart)
+
+		partType, _, errType := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		require.NoError(t, errType)
+
+		// NextPart decodes quoted-printable parts transparently.
+		content, errRead := io.ReadAll(part)
+		require.NoError(t, errRead)
+		parts[partType] = string(content)
+	}
+
+	return subject, parts
+}
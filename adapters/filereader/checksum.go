@@ -0,0 +1,49 @@
+package filereader
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+const (
+	checksumPrefix = "sha256:"
+	etagPrefix     = "etag:"
+)
+
+// fileChecksum is the SHA-256 of the content of a file, prefixed with the name of the algorithm.
+func fileChecksum(ctx context.Context, filePath string) (string, error) {
+	fail := func(err error) (string, error) {
+		return "", fmt.Errorf("filereader: fileChecksum: %w", err)
+	}
+
+	file, err := openCVSFile(filePath)
+	if err != nil {
+		return fail(err)
+	}
+
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, contextReader{ctx: ctx, reader: file}); err != nil {
+		return fail(err)
+	}
+
+	return checksumPrefix + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// contextReader fails with the error of the context once it is done, so that reading a large file can be cancelled.
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (reader contextReader) Read(p []byte) (int, error) {
+	if err := reader.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return reader.reader.Read(p)
+}
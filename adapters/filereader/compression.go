@@ -0,0 +1,458 @@
+package filereader
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Compression is how the content of a file is compressed, whatever its format.
+type Compression string
+
+const (
+	CompressionNone  Compression = ""
+	CompressionGzip  Compression = "gzip"
+	CompressionBzip2 Compression = "bzip2"
+	CompressionZip   Compression = "zip"
+)
+
+// MemberPolicy decides how the members of a zip archive are read.
+type MemberPolicy int
+
+const (
+	// ConcatenateMembers reads the members of an archive one after the other as a single file, each one decoded
+	// with its own format and header.
+	ConcatenateMembers MemberPolicy = iota
+	// SeparateMembers makes each member of an archive an input of its own. See Inputs.
+	SeparateMembers
+)
+
+const (
+	// DefaultMaxDecompressedSize is 4 GiB, four times DefaultMaxObjectSize.
+	DefaultMaxDecompressedSize = 4 << 30
+	// DefaultMaxRatio is ten times what CSV files usually compress by, which only decompression bombs go beyond.
+	DefaultMaxRatio = 100
+	// DefaultMaxMembers is far above the files of a nightly export, far below the millions of a zip bomb.
+	DefaultMaxMembers = 1000
+
+	// ratioThreshold is the number of bytes decompressed from which the ratio is checked, as small or repetitive
+	// files compress well beyond any sensible ratio.
+	ratioThreshold = 1 << 20
+	// zipWindowSize is the least read from archives read in place, so that the small reads of the zip package do not
+	// each cost a request when the archive is an S3 object.
+	zipWindowSize = 1 << 20
+	// magicLength is the length of the longest magic number of compressions.
+	magicLength = 4
+)
+
+// CompressionConfig is how compressed files are guarded against decompression bombs, and how the members of zip
+// archives are read. Its zero value concatenates members, with the default limits.
+type CompressionConfig struct {
+	// MaxDecompressedSize is the number of bytes decompressed from a file at most, the members of an archive
+	// together. DefaultMaxDecompressedSize when zero, no limit when negative.
+	MaxDecompressedSize int64
+	// MaxRatio is how many times larger than the bytes they are decompressed from the decompressed bytes can be.
+	// DefaultMaxRatio when zero, no limit when negative.
+	MaxRatio int64
+	// MaxMembers is the number of files a zip archive can hold at most. DefaultMaxMembers when zero, no limit when
+	// negative.
+	MaxMembers int
+	// Members decides whether the members of a zip archive are read as one input or as separate ones.
+	Members MemberPolicy
+}
+
+// compressions tell compressed files apart by the extension of their name, their media type or their magic number.
+var compressions = []struct { //nolint:gochecknoglobals // Read only
+	compression Compression
+	extensions  []string
+	mediaTypes  []string
+	magic       []byte
+}{
+	{CompressionGzip, []string{".gz", ".gzip"}, []string{"application/gzip", "application/x-gzip"}, []byte{0x1f, 0x8b}},
+	{CompressionBzip2, []string{".bz2", ".bzip2"}, []string{"application/x-bzip2"}, []byte("BZh")},
+	{CompressionZip, []string{".zip"}, []string{"application/zip", "application/x-zip-compressed"}, []byte("PK\x03\x04")},
+}
+
+// detectCompression tells how the source is compressed from the extension of its name, its media type, or else its
+// first bytes.
+func detectCompression(source Source, head []byte) Compression {
+	extension := strings.ToLower(path.Ext(source.Name))
+	for _, candidate := range compressions {
+		if extension != "" && slices.Contains(candidate.extensions, extension) {
+			return candidate.compression
+		}
+	}
+
+	for _, candidate := range compressions {
+		if source.MediaType != "" && slices.Contains(candidate.mediaTypes, source.MediaType) {
+			return candidate.compression
+		}
+	}
+
+	for _, candidate := range compressions {
+		if bytes.HasPrefix(head, candidate.magic) {
+			return candidate.compression
+		}
+	}
+
+	return CompressionNone
+}
+
+// StripCompression is the name without the extension of its compression, so that the format of transactions.csv.gz
+// is told by .csv.
+func StripCompression(name string) string {
+	extension := strings.ToLower(path.Ext(name))
+	for _, candidate := range compressions {
+		if slices.Contains(candidate.extensions, extension) {
+			return strings.TrimSuffix(name, path.Ext(name))
+		}
+	}
+
+	return name
+}
+
+// decompress decodes the content of the source, decompressing it on the fly when it is compressed.
+func (registry *Registry) decompress(source Source, config Config) (iter.Seq2[Row, error], error) {
+	content := bufio.NewReaderSize(source, sniffLength)
+	if _, err := content.Peek(1); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrFileIsEmpty
+		}
+
+		return nil, err
+	}
+
+	head, _ := content.Peek(magicLength)
+	guard := &decompressionGuard{config: config.Compression}
+	decompressed := Source{Name: StripCompression(source.Name)}
+
+	switch detectCompression(source, head) {
+	case CompressionGzip:
+		reader, err := gzip.NewReader(guard.compressedReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDecompression, err)
+		}
+
+		return registry.decode(decompressed, guard.decompressedReader(reader), config)
+	case CompressionBzip2:
+		return registry.decode(decompressed, guard.decompressedReader(bzip2.NewReader(guard.compressedReader(content))),
+			config)
+	case CompressionZip:
+		archive, size, err := openZip(source, content)
+		if err != nil {
+			return nil, err
+		}
+
+		members, err := zipMembers(archive, config.Compression)
+		if err != nil {
+			return nil, err
+		}
+
+		return registry.decodeMembers(members, size, guard, config), nil
+	default:
+		return registry.decode(source, content, config)
+	}
+}
+
+// decode decodes the content with the decoder of its format, which is detected from the source when not forced.
+func (registry *Registry) decode(source Source, content io.Reader, config Config) (iter.Seq2[Row, error], error) {
+	// Only the first bytes read are looked at, so that the file is still streamed.
+	buffered := bufio.NewReaderSize(content, sniffLength)
+	if _, err := buffered.Peek(1); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrFileIsEmpty
+		}
+
+		return nil, err
+	}
+
+	format := config.Format
+	if format == "" {
+		format = registry.detect(source, func() []byte {
+			head, _ := buffered.Peek(sniffLength)
+			return head
+		})
+	}
+
+	spec, err := registry.spec(format)
+	if err != nil {
+		return nil, err
+	}
+
+	return spec.Decoder(buffered, config), nil
+}
+
+// decodeMembers yields the rows of the members of an archive one after the other, their errors pointing to the
+// member they come from. Empty members are left out.
+func (registry *Registry) decodeMembers(
+	members []*zip.File, archiveSize int64, guard *decompressionGuard, config Config,
+) iter.Seq2[Row, error] {
+	return func(yield func(Row, error) bool) {
+		decoded := 0
+
+		for _, member := range members {
+			empty, more := registry.decodeMember(member, archiveSize, guard, config, yield)
+			if !empty {
+				decoded++
+			}
+
+			if !more {
+				return
+			}
+		}
+
+		if decoded == 0 {
+			yield(Row{}, ErrFileIsEmpty)
+		}
+	}
+}
+
+// decodeMember yields the rows of the member, telling whether it was empty and whether to go on with the next one.
+func (registry *Registry) decodeMember(
+	member *zip.File, archiveSize int64, guard *decompressionGuard, config Config, yield func(Row, error) bool,
+) (bool, bool) {
+	content, err := openZipMember(member, archiveSize, guard)
+	if err != nil {
+		return false, yield(Row{}, fmt.Errorf("%s: %w", member.Name, err))
+	}
+
+	defer content.Close()
+
+	rows, err := registry.decode(Source{Name: member.Name}, content, config)
+	if errors.Is(err, ErrFileIsEmpty) {
+		return true, true
+	}
+
+	if err != nil {
+		return false, yield(Row{}, fmt.Errorf("%s: %w", member.Name, err))
+	}
+
+	for row, errRow := range rows {
+		if errRow != nil {
+			return false, yield(Row{}, fmt.Errorf("%s: %w", member.Name, errRow))
+		}
+
+		for _, rowErr := range row.Errors {
+			rowErr.File = member.Name
+		}
+
+		if !yield(row, nil) {
+			return false, false
+		}
+	}
+
+	return false, true
+}
+
+// sizedReaderAt is content that can be read at any offset, as zip archives are, e.g. local files or S3 objects.
+type sizedReaderAt interface {
+	io.ReaderAt
+	Size() int64
+}
+
+// openZip reads the archive in place when the source can be read at any offset, or else from memory, up to
+// DefaultMaxObjectSize.
+func openZip(source Source, content io.Reader) (*zip.Reader, int64, error) {
+	fail := func(err error) (*zip.Reader, int64, error) {
+		return nil, 0, fmt.Errorf("filereader: openZip: %w", err)
+	}
+
+	var archive io.ReaderAt
+	var size int64
+
+	if sized, ok := source.ReadCloser.(sizedReaderAt); ok {
+		archive, size = &windowReader{reader: sized, size: sized.Size()}, sized.Size()
+	} else {
+		data, err := io.ReadAll(io.LimitReader(content, DefaultMaxObjectSize+1))
+		if err != nil {
+			return fail(err)
+		}
+
+		if len(data) > DefaultMaxObjectSize {
+			return fail(fmt.Errorf("%w: more than %d bytes", ErrObjectTooLarge, DefaultMaxObjectSize))
+		}
+
+		archive, size = bytes.NewReader(data), int64(len(data))
+	}
+
+	reader, err := zip.NewReader(archive, size)
+	if err != nil {
+		return fail(fmt.Errorf("%w: %w", ErrDecompression, err))
+	}
+
+	return reader, size, nil
+}
+
+// zipMembers are the files of the archive, without its directories and the metadata macOS adds to the archives it
+// creates.
+func zipMembers(archive *zip.Reader, config CompressionConfig) ([]*zip.File, error) {
+	if maxMembers := config.maxMembers(); maxMembers >= 0 && len(archive.File) > maxMembers {
+		return nil, fmt.Errorf("filereader: zipMembers: %w: %d members, at most %d",
+			ErrArchiveTooLarge, len(archive.File), maxMembers)
+	}
+
+	members := make([]*zip.File, 0, len(archive.File))
+	for _, member := range archive.File {
+		if member.FileInfo().IsDir() || strings.HasPrefix(member.Name, "__MACOSX/") {
+			continue
+		}
+
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+// openZipMember decompresses the member under the guard. Archives and compressed files within the archive are
+// refused, rather than decompressed again, as nesting them is how the worst decompression bombs are made.
+func openZipMember(member *zip.File, archiveSize int64, guard *decompressionGuard) (io.ReadCloser, error) {
+	fail := func(err error) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("filereader: openZipMember: %w", err)
+	}
+
+	reader, err := member.Open()
+	if err != nil {
+		return fail(fmt.Errorf("%w: %w", ErrDecompression, err))
+	}
+
+	// The declared size of the member may lie, but it cannot be read beyond the archive.
+	guard.compressed += min(int64(member.CompressedSize64), archiveSize) //nolint:gosec // Bounded by the archive
+
+	content := bufio.NewReaderSize(guard.decompressedReader(reader), sniffLength)
+	head, _ := content.Peek(magicLength)
+
+	if detectCompression(Source{Name: member.Name}, head) != CompressionNone {
+		reader.Close()
+		return fail(fmt.Errorf("%w: %s is itself compressed", ErrDecompression, member.Name))
+	}
+
+	return readCloser{Reader: content, close: reader.Close}, nil
+}
+
+// decompressionGuard counts the bytes decompressed from a file, the members of an archive together, and stops
+// reading it once there are more than the config allows, as decompression bombs would make.
+type decompressionGuard struct {
+	config       CompressionConfig
+	compressed   int64
+	decompressed int64
+}
+
+// compressedReader counts the bytes decompressed from.
+func (guard *decompressionGuard) compressedReader(compressed io.Reader) io.Reader {
+	return readerFunc(func(p []byte) (int, error) {
+		n, err := compressed.Read(p)
+		guard.compressed += int64(n)
+
+		return n, err
+	})
+}
+
+// decompressedReader fails once too many bytes were decompressed, and tells failures to decompress by
+// ErrDecompression.
+func (guard *decompressionGuard) decompressedReader(decompressed io.Reader) io.Reader {
+	return readerFunc(func(p []byte) (int, error) {
+		n, err := decompressed.Read(p)
+		guard.decompressed += int64(n)
+
+		if errGuard := guard.check(); errGuard != nil {
+			return n, errGuard
+		}
+
+		if err != nil && !errors.Is(err, io.EOF) {
+			return n, fmt.Errorf("%w: %w", ErrDecompression, err)
+		}
+
+		return n, err
+	})
+}
+
+func (guard *decompressionGuard) check() error {
+	if maxSize := guard.config.maxDecompressedSize(); maxSize >= 0 && guard.decompressed > maxSize {
+		return fmt.Errorf("%w: more than %d bytes decompressed", ErrArchiveTooLarge, maxSize)
+	}
+
+	maxRatio := guard.config.maxRatio()
+	if maxRatio >= 0 && guard.decompressed > ratioThreshold && guard.decompressed > maxRatio*max(guard.compressed, 1) {
+		return fmt.Errorf("%w: decompressed more than %d times larger", ErrArchiveTooLarge, maxRatio)
+	}
+
+	return nil
+}
+
+// windowReader reads windows of at least zipWindowSize bytes, and answers the reads within the last one from
+// memory.
+type windowReader struct {
+	reader io.ReaderAt
+	size   int64
+	window []byte
+	offset int64
+}
+
+func (reader *windowReader) ReadAt(p []byte, offset int64) (int, error) {
+	end := offset + int64(len(p))
+	if offset >= reader.offset && end <= reader.offset+int64(len(reader.window)) {
+		return copy(p, reader.window[offset-reader.offset:]), nil
+	}
+
+	if len(p) >= zipWindowSize || offset >= reader.size {
+		return reader.reader.ReadAt(p, offset)
+	}
+
+	window := make([]byte, min(zipWindowSize, reader.size-offset))
+	n, err := reader.reader.ReadAt(window, offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+
+	reader.window, reader.offset = window[:n], offset
+
+	copied := copy(p, reader.window)
+	if copied < len(p) {
+		return copied, io.EOF
+	}
+
+	return copied, nil
+}
+
+// readCloser is a reader closed by a function, e.g. that of the file it is read from.
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (reader readCloser) Close() error {
+	return reader.close()
+}
+
+func (config CompressionConfig) maxDecompressedSize() int64 {
+	if config.MaxDecompressedSize == 0 {
+		return DefaultMaxDecompressedSize
+	}
+
+	return config.MaxDecompressedSize
+}
+
+func (config CompressionConfig) maxRatio() int64 {
+	if config.MaxRatio == 0 {
+		return DefaultMaxRatio
+	}
+
+	return config.MaxRatio
+}
+
+func (config CompressionConfig) maxMembers() int {
+	if config.MaxMembers == 0 {
+		return DefaultMaxMembers
+	}
+
+	return config.MaxMembers
+}
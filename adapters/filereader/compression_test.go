@@ -0,0 +1,492 @@
+package filereader_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stori/adapters/filereader"
+	"stori/model"
+	"stori/test"
+)
+
+// member is a file of a zip archive.
+type member struct {
+	name    string
+	content string
+}
+
+func gzipContent(t *testing.T, content []byte) []byte {
+	t.Helper()
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err := writer.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	return compressed.Bytes()
+}
+
+func zipContent(t *testing.T, members ...member) []byte {
+	t.Helper()
+
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	for _, file := range members {
+		memberWriter, err := writer.Create(file.name)
+		require.NoError(t, err)
+		_, err = memberWriter.Write([]byte(file.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	return archive.Bytes()
+}
+
+// writeTemp writes the content to a file of a temporary directory, returning its path.
+func writeTemp(t *testing.T, name string, content []byte) string {
+	t.Helper()
+
+	path := t.TempDir() + "/" + name
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+
+	return path
+}
+
+func readTestdata(t *testing.T, filename string) []byte {
+	t.Helper()
+
+	content, err := os.ReadFile("testdata/" + filename)
+	require.NoError(t, err)
+
+	return content
+}
+
+// newReader is the reader NewReader builds for the URI.
+func newReader(t *testing.T, uri string, config filereader.Config) filereader.FileReader {
+	t.Helper()
+
+	reader, err := filereader.NewReader(uri, config)
+	require.NoError(t, err)
+
+	return reader
+}
+
+// localTransactions are the transactions of the file of the testdata folder.
+func localTransactions(t *testing.T, filename string) []model.Transaction {
+	t.Helper()
+
+	transactions, err := newReader(t, "testdata/"+filename, filereader.Config{}).ReadTransactions(context.Background())
+	require.NoError(t, err)
+
+	return transactions
+}
+
+func TestReadTransactions_WhenFileIsCompressed_SameAsUncompressed(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	content := readTestdata(t, "several_transactions.csv")
+	expected := localTransactions(t, "several_transactions.csv")
+
+	testCases := []struct {
+		name string
+		path string
+	}{
+		{name: "When gzip", path: writeTemp(t, "transactions.csv.gz", gzipContent(t, content))},
+		{name: "When gzip without extension", path: writeTemp(t, "transactions", gzipContent(t, content))},
+		{name: "When bzip2", path: "testdata/several_transactions.csv.bz2"},
+		{name: "When zip", path: writeTemp(t, "transactions.zip",
+			zipContent(t, member{name: "transactions.csv", content: string(content)}))},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			sut := newReader(t, tc.path, filereader.Config{})
+
+			// Act
+			transactions, err := sut.ReadTransactions(context.Background())
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, expected, transactions)
+		})
+	}
+}
+
+func TestReadTransactions_WhenFormatIsCompressed_DetectedWithoutCompressionExtension(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	path := writeTemp(t, "statement.qfx.gz", gzipContent(t, readTestdata(t, "statement_xml.qfx")))
+
+	sut, err := filereader.NewReader(path, filereader.Config{})
+	require.NoError(t, err)
+
+	// Act
+	transactions, err := sut.ReadTransactions(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, localTransactions(t, "statement_xml.qfx"), transactions)
+}
+
+func TestReadTransactions_WhenZipHasSeveralMembers_Concatenated(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	archive := zipContent(t,
+		member{name: "exports/", content: ""},
+		member{name: "exports/first.csv", content: string(readTestdata(t, "single_transaction.csv"))},
+		member{name: "exports/empty.csv", content: ""},
+		member{name: "__MACOSX/exports/._first.csv", content: "resource fork"},
+		member{name: "exports/second.qfx", content: string(readTestdata(t, "statement_xml.qfx"))},
+	)
+	uris := map[string]string{"local": writeTemp(t, "nightly.zip", archive), "streamed": "mem://nightly.zip"}
+	registry := filereader.NewRegistry()
+	registry.RegisterTransport("mem", memoryTransport{"nightly.zip": string(archive)})
+
+	expected := append(localTransactions(t, "single_transaction.csv"), localTransactions(t, "statement_xml.qfx")...)
+
+	for name, uri := range uris {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			sut, err := registry.NewReader(uri, filereader.Config{})
+			require.NoError(t, err)
+
+			// Act
+			transactions, err := sut.ReadTransactions(context.Background())
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, expected, transactions)
+		})
+	}
+}
+
+func TestValidate_WhenZipMemberHasInvalidRows_ErrorsPointToMember(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	path := writeTemp(t, "nightly.zip", zipContent(t,
+		member{name: "first.csv", content: string(readTestdata(t, "several_transactions.csv"))},
+		member{name: "second.csv", content: string(readTestdata(t, "several_invalid_rows.csv"))},
+	))
+
+	sut, err := filereader.NewReader(path, filereader.Config{})
+	require.NoError(t, err)
+
+	// Act
+	report, err := sut.Validate(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.NotEmpty(t, report.Errors)
+	for _, rowErr := range report.Errors {
+		assert.Equal(t, "second.csv", rowErr.File)
+		assert.True(t, strings.HasPrefix(rowErr.Error(), "second.csv: line "), rowErr.Error())
+	}
+}
+
+func TestInputs_WhenMembersAreSeparate_OneInputPerMember(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	path := writeTemp(t, "nightly.zip", zipContent(t,
+		member{name: "first.csv", content: string(readTestdata(t, "single_transaction.csv"))},
+		member{name: "second.csv", content: string(readTestdata(t, "several_transactions.csv"))},
+	))
+	config := filereader.Config{Compression: filereader.CompressionConfig{Members: filereader.SeparateMembers}}
+
+	// Act
+	inputs, err := filereader.Inputs(context.Background(), path, config)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, inputs, 2)
+	assert.Equal(t, path+"#first.csv", inputs[0].URI)
+	assert.Equal(t, path+"#second.csv", inputs[1].URI)
+
+	first, err := inputs[0].Reader.ReadTransactions(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, localTransactions(t, "single_transaction.csv"), first)
+
+	second, err := inputs[1].Reader.ReadTransactions(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, localTransactions(t, "several_transactions.csv"), second)
+
+	firstChecksum, err := inputs[0].Reader.Checksum(context.Background())
+	require.NoError(t, err)
+	secondChecksum, err := inputs[1].Reader.Checksum(context.Background())
+	require.NoError(t, err)
+	assert.NotEqual(t, firstChecksum, secondChecksum)
+}
+
+func TestInputs_WhenMembersAreQuarantined_OneFilePerMember(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	invalid := string(readTestdata(t, "several_invalid_rows.csv"))
+	path := writeTemp(t, "nightly.zip", zipContent(t,
+		member{name: "first.csv", content: invalid},
+		member{name: "exports/second.json", content: `{"id": 7, "date": "7/15", "transaction": "abc"}`},
+	))
+	dir := t.TempDir()
+	config := filereader.Config{
+		InvalidRows:    filereader.QuarantineInvalidRows,
+		QuarantinePath: dir + "/quarantine.csv",
+		Compression:    filereader.CompressionConfig{Members: filereader.SeparateMembers},
+	}
+
+	inputs, err := filereader.Inputs(context.Background(), path, config)
+	require.NoError(t, err)
+	require.Len(t, inputs, 2)
+
+	// Act
+	for _, input := range inputs {
+		_, errRead := input.Reader.ReadTransactions(context.Background())
+		require.NoError(t, errRead)
+	}
+
+	// Assert
+	first, err := os.ReadFile(dir + "/quarantine.first.csv")
+	require.NoError(t, err)
+	assert.Contains(t, string(first), "line,error,id,date,transaction\n")
+
+	second, err := os.ReadFile(dir + "/quarantine.exports_second.json.csv")
+	require.NoError(t, err)
+	assert.Contains(t, string(second), "line,error,record\n")
+
+	_, err = os.Stat(dir + "/quarantine.csv")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestInputs_WhenFileIsNotAnArchive_SingleInput(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		members filereader.MemberPolicy
+	}{
+		{name: "When members are concatenated", members: filereader.ConcatenateMembers},
+		{name: "When members are separate", members: filereader.SeparateMembers},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			path := "testdata/several_transactions.csv.bz2"
+			config := filereader.Config{Compression: filereader.CompressionConfig{Members: tc.members}}
+
+			// Act
+			inputs, err := filereader.Inputs(context.Background(), path, config)
+
+			// Assert
+			require.NoError(t, err)
+			require.Len(t, inputs, 1)
+			assert.Equal(t, path, inputs[0].URI)
+		})
+	}
+}
+
+func TestReadTransactions_WhenDecompressionBomb_Error(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	header, row := "id,date,transaction\n", "0,7/15,+60.5\n"
+	rows := strings.Repeat(row, 1<<18)
+
+	testCases := []struct {
+		name        string
+		filename    string
+		content     []byte
+		compression filereader.CompressionConfig
+		expectedErr error
+	}{
+		{
+			name:        "When ratio is too high",
+			filename:    "bomb.csv.gz",
+			content:     gzipContent(t, []byte(header+rows)),
+			expectedErr: filereader.ErrArchiveTooLarge,
+		},
+		{
+			name:        "When decompressed size is too large",
+			filename:    "bomb.csv.gz",
+			content:     gzipContent(t, []byte(header+rows)),
+			compression: filereader.CompressionConfig{MaxDecompressedSize: 1 << 10, MaxRatio: -1},
+			expectedErr: filereader.ErrArchiveTooLarge,
+		},
+		{
+			name:     "When members are too large together",
+			filename: "bomb.zip",
+			content: zipContent(t,
+				member{name: "first.csv", content: header + rows[:5000*len(row)]},
+				member{name: "second.csv", content: header + rows[:5000*len(row)]},
+			),
+			compression: filereader.CompressionConfig{MaxDecompressedSize: 6000 * int64(len(row)), MaxRatio: -1},
+			expectedErr: filereader.ErrArchiveTooLarge,
+		},
+		{
+			name:     "When too many members",
+			filename: "bomb.zip",
+			content: zipContent(t,
+				member{name: "first.csv", content: header},
+				member{name: "second.csv", content: header},
+			),
+			compression: filereader.CompressionConfig{MaxMembers: 1},
+			expectedErr: filereader.ErrArchiveTooLarge,
+		},
+		{
+			name:     "When members are archives",
+			filename: "nested.zip",
+			content: zipContent(t,
+				member{name: "inner.zip", content: string(zipContent(t, member{name: "a.csv", content: header}))},
+			),
+			expectedErr: filereader.ErrDecompression,
+		},
+		{
+			name:        "When content is not compressed as its extension tells",
+			filename:    "transactions.csv.gz",
+			content:     readTestdata(t, "several_transactions.csv"),
+			expectedErr: filereader.ErrDecompression,
+		},
+		{
+			name:        "When archive is corrupted",
+			filename:    "transactions.zip",
+			content:     zipContent(t, member{name: "a.csv", content: header})[:20],
+			expectedErr: filereader.ErrDecompression,
+		},
+		{
+			name:        "When decompressed content is empty",
+			filename:    "transactions.csv.gz",
+			content:     gzipContent(t, nil),
+			expectedErr: filereader.ErrFileIsEmpty,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			path := writeTemp(t, tc.filename, tc.content)
+
+			sut, err := filereader.NewReader(path, filereader.Config{Compression: tc.compression})
+			require.NoError(t, err)
+
+			// Act
+			_, err = sut.ReadTransactions(context.Background())
+
+			// Assert
+			require.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestReadTransactionsFromS3_WhenZip_ReadInPlaceByRanges(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	var content strings.Builder
+	content.WriteString("id,date,transaction\n")
+	for id := range 100_000 {
+		fmt.Fprintf(&content, "%d,7/%d,+%d.%d\n", id, id%28+1, id%997, id%10)
+	}
+
+	expected, err := newReader(t, writeTemp(t, "transactions.csv", []byte(content.String())),
+		filereader.Config{}).ReadTransactions(context.Background())
+	require.NoError(t, err)
+
+	fake := test.NewFakeS3(t)
+	fake.Put(minioBucket, "nightly.zip", test.FakeObject{Content: zipContent(t,
+		member{name: "transactions.csv", content: content.String()},
+	)})
+
+	sut := newReader(t, s3Prefix+"/nightly.zip", filereader.Config{
+		S3: filereader.S3Config{Endpoint: fake.URL(), PathStyle: true, Anonymous: true},
+	})
+
+	// Act
+	transactions, err := sut.ReadTransactions(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, expected, transactions)
+	assert.NotEmpty(t, fake.Ranges())
+	assert.Less(t, len(fake.Ranges()), 10, "small reads are answered from the window they fall in")
+}
+
+func TestReadTransactionsFromS3_WhenGzip_Decompressed(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	fake := test.NewFakeS3(t)
+	fake.Put(minioBucket, "nightly/transactions.csv.gz",
+		test.FakeObject{Content: gzipContent(t, readTestdata(t, "several_transactions.csv"))})
+
+	sut := newReader(t, s3Prefix+"/nightly/transactions.csv.gz", filereader.Config{
+		S3: filereader.S3Config{Endpoint: fake.URL(), PathStyle: true, Anonymous: true},
+	})
+
+	// Act
+	transactions, err := sut.ReadTransactions(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, localTransactions(t, "several_transactions.csv"), transactions)
+}
+
+// Compressed content read without a name falls back to its magic number, e.g. piped through the standard input.
+func TestReadTransactions_WhenCompressedWithoutName_DetectedByMagicNumber(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	registry := filereader.NewRegistry()
+	registry.RegisterTransport("mem", unnamedTransport(gzipContent(t, readTestdata(t, "several_transactions.csv"))))
+
+	sut, err := registry.NewReader("mem://anything", filereader.Config{})
+	require.NoError(t, err)
+
+	// Act
+	transactions, err := sut.ReadTransactions(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, localTransactions(t, "several_transactions.csv"), transactions)
+}
+
+// unnamedTransport serves the content without a name nor a media type.
+type unnamedTransport []byte
+
+func (transport unnamedTransport) Open(context.Context, string, filereader.Config) (filereader.Source, error) {
+	return filereader.Source{ReadCloser: io.NopCloser(bytes.NewReader(transport))}, nil
+}
+
+func (unnamedTransport) Checksum(context.Context, string, filereader.Config) (string, error) {
+	return "", nil
+}
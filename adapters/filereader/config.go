@@ -0,0 +1,83 @@
+package filereader
+
+import "time"
+
+type (
+	// Config holds the parsing options shared by every reader, and where the s3 transport finds objects. Its zero
+	// value is ready to use.
+	Config struct {
+		// DefaultYear is given to dates without a year. The current year is used when it is zero.
+		DefaultYear int
+		// YearInference decides how the year of dates without one evolves along the file.
+		YearInference YearInference
+		// InvalidRows decides what happens to invalid rows. Files are rejected at the first one by default.
+		InvalidRows InvalidRowPolicy
+		// QuarantinePath is the CSV file invalid rows are written to when InvalidRows is QuarantineInvalidRows.
+		// The members of an archive read separately are quarantined next to it, each to a file named after it.
+		QuarantinePath string
+		// Columns tells where the fields of a transaction are found. The default layout is used when it is zero.
+		Columns ColumnMapping
+		// Format forces the format files are decoded with, rather than detecting it.
+		Format Format
+		// Compression tells how compressed files and the members of zip archives are read.
+		Compression CompressionConfig
+		// S3 tells how s3:// URIs are read. Other transports ignore it.
+		S3 S3Config
+		// HTTP tells how http:// and https:// URLs are downloaded. Other transports ignore it.
+		HTTP HTTPConfig
+	}
+
+	// HTTPConfig is how files are downloaded from http:// and https:// URLs, e.g. presigned S3 links. Its zero value
+	// downloads without credentials, with the default limits.
+	HTTPConfig struct {
+		// Timeout of a whole download, body included. DefaultHTTPTimeout when zero.
+		Timeout time.Duration
+		// MaxBodySize is the size in bytes of the largest file downloaded, DefaultMaxObjectSize when zero. Files are
+		// downloaded whatever their size when it is negative.
+		MaxBodySize int64
+		// BearerToken is sent in the Authorization header when set.
+		BearerToken string
+		// Username and Password are sent with basic authentication when Username is set and BearerToken is not.
+		Username string
+		Password string
+		// ContentTypes are the media types of the files accepted, DefaultContentTypes when empty. Responses without
+		// a Content-Type are always accepted.
+		ContentTypes []string
+		// version is shared by the copies of a reader, so that its checksum and all of its reads are of the same
+		// version of the file. Readers of NewReader have one.
+		version *httpVersion
+	}
+
+	// S3Config is where and as whom objects are read. Its zero value uses the region and the credentials the SDK
+	// finds in the environment, the shared config files or the role of the instance, as the AWS CLI does.
+	S3Config struct {
+		// Region of the bucket. The region of the environment or the profile is used when empty, else us-east-1.
+		Region string
+		// Profile of the shared config and credentials files. The default profile is used when empty.
+		Profile string
+		// Anonymous reads public buckets without credentials, rather than looking for them.
+		Anonymous bool
+		// Endpoint overrides the S3 endpoint, e.g. http://localhost:9000 for MinIO or LocalStack.
+		Endpoint string
+		// PathStyle addresses buckets as endpoint/bucket/key rather than bucket.endpoint/key, as MinIO needs.
+		PathStyle bool
+		// MaxObjectSize is the size in bytes of the largest object read, DefaultMaxObjectSize when zero. Objects are
+		// read whatever their size when it is negative.
+		MaxObjectSize int64
+		// PartSize is the size in bytes of the ranges objects are read by, DefaultPartSize when zero.
+		PartSize int64
+		// version is shared by the copies of a reader, so that its checksum and all of its reads are of the same
+		// version of the object. Readers of NewReader have one.
+		version *objectVersion
+	}
+
+	YearInference int
+)
+
+const (
+	// YearFixed gives every date without a year the DefaultYear.
+	YearFixed YearInference = iota
+	// YearRollover assumes the file is sorted by date: it starts at the DefaultYear and moves to the next year
+	// every time the month goes backwards, e.g. from 12/28 to 1/03.
+	YearRollover
+)
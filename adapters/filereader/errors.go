@@ -5,10 +5,19 @@ import "errors"
 var ErrFileNotFound = errors.New("file not found")
 var ErrInvalidFile = errors.New("invalid file")
 var ErrInvalidHeader = errors.New("invalid header")
+var ErrInvalidMapping = errors.New("invalid column mapping")
 var ErrFileIsEmpty = errors.New("file is empty")
+var ErrInvalidID = errors.New("invalid id")
 var ErrInvalidAmount = errors.New("invalid amount")
+var ErrInvalidAccount = errors.New("invalid account")
 var ErrInvalidDateFormat = errors.New("invalid date format")
 var ErrInvalidURI = errors.New("invalid URI")
 var ErrS3Connection = errors.New("error connecting to S3")
-var ErrFileCreation = errors.New("error creating temporary file")
 var ErrDownloadFile = errors.New("error downloading file")
+var ErrObjectTooLarge = errors.New("object too large")
+var ErrQuarantine = errors.New("error quarantining invalid rows")
+var ErrUnsupportedContentType = errors.New("unsupported content type")
+var ErrUnsupportedScheme = errors.New("unsupported URI scheme")
+var ErrUnsupportedFormat = errors.New("unsupported file format")
+var ErrDecompression = errors.New("error decompressing file")
+var ErrArchiveTooLarge = errors.New("archive too large")
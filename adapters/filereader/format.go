@@ -0,0 +1,39 @@
+package filereader
+
+import (
+	"context"
+	"iter"
+	"mime"
+
+	"stori/model"
+)
+
+// Format is the layout of the content of a file, regardless of where it is read from.
+type Format string
+
+const (
+	FormatCSV Format = "csv"
+	FormatOFX Format = "ofx"
+	// FormatJSON is either a JSON array of transactions or newline-delimited JSON, one transaction per line.
+	FormatJSON Format = "json"
+
+	sniffLength = 512
+)
+
+// FileReader reads the transactions of a file, whatever its transport and its format.
+type FileReader interface {
+	ReadTransactions(ctx context.Context) ([]model.Transaction, error)
+	StreamTransactions(ctx context.Context) iter.Seq2[model.Transaction, error]
+	Validate(ctx context.Context) (ValidationReport, error)
+	Checksum(ctx context.Context) (string, error)
+}
+
+// mediaTypeOf is the media type of a Content-Type, without its parameters, e.g. text/csv for text/csv; charset=utf-8.
+func mediaTypeOf(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+
+	return mediaType
+}
@@ -0,0 +1,298 @@
+package filereader
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultHTTPTimeout leaves time to download large files, while not hanging on a server that stopped answering.
+const DefaultHTTPTimeout = 2 * time.Minute
+
+// DefaultContentTypes are the media types CSV, OFX and JSON files are served with, XML ones included for OFX 2,
+// those of the compressed files they can be read from, and the generic ones S3 gives to objects uploaded without a
+// type.
+var DefaultContentTypes = []string{ //nolint:gochecknoglobals // Read only
+	"text/csv",
+	"text/plain",
+	"application/csv",
+	"application/vnd.ms-excel",
+	"application/x-ofx",
+	"application/ofx",
+	"application/x-qfx",
+	"application/xml",
+	"text/xml",
+	"application/json",
+	"application/x-ndjson",
+	"application/jsonl",
+	"application/jsonlines",
+	"application/gzip",
+	"application/x-gzip",
+	"application/x-bzip2",
+	"application/zip",
+	"application/x-zip-compressed",
+	"application/octet-stream",
+	"binary/octet-stream",
+}
+
+// httpTransport downloads files given by http:// and https:// URLs, such as presigned S3 links, streaming them
+// without writing them to disk.
+type httpTransport struct{}
+
+// httpVersion is the validator of the file the first time it was requested, either by a checksum or by a read: its
+// ETag, or else its Last-Modified date, as weak ETags cannot be matched. Files served with neither are not pinned.
+type httpVersion struct {
+	mu           sync.Mutex
+	etag         string
+	lastModified string
+}
+
+// pin keeps the validator of the response unless one was pinned before.
+func (version *httpVersion) pin(response *http.Response) {
+	if version == nil {
+		return
+	}
+
+	version.mu.Lock()
+	defer version.mu.Unlock()
+
+	if version.etag != "" || version.lastModified != "" {
+		return
+	}
+
+	if etag := response.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		version.etag = etag
+		return
+	}
+
+	version.lastModified = response.Header.Get("Last-Modified")
+}
+
+// condition makes the request fail with 412 Precondition Failed when the file is not the version pinned anymore.
+func (version *httpVersion) condition(request *http.Request) {
+	if version == nil {
+		return
+	}
+
+	version.mu.Lock()
+	defer version.mu.Unlock()
+
+	switch {
+	case version.etag != "":
+		request.Header.Set("If-Match", version.etag)
+	case version.lastModified != "":
+		request.Header.Set("If-Unmodified-Since", version.lastModified)
+	}
+}
+
+// httpFile is the file at a URL, requested as the config tells.
+type httpFile struct {
+	fileURL string
+	config  Config
+	client  *http.Client
+}
+
+func newHTTPFile(fileURL string, config Config) httpFile {
+	timeout := config.HTTP.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHTTPTimeout
+	}
+
+	return httpFile{fileURL: fileURL, config: config, client: &http.Client{Timeout: timeout}}
+}
+
+func (httpTransport) Open(ctx context.Context, uri string, config Config) (Source, error) {
+	file := newHTTPFile(uri, config)
+
+	response, err := file.get(ctx)
+	if err != nil {
+		return Source{}, err
+	}
+
+	body := struct {
+		io.Reader
+		io.Closer
+	}{Reader: file.limit(response), Closer: response.Body}
+
+	return Source{
+		ReadCloser: body,
+		Name:       response.Request.URL.Path,
+		MediaType:  mediaTypeOf(response.Header.Get("Content-Type")),
+	}, nil
+}
+
+// Checksum fingerprints the file with its ETag when the server gives one, as S3 does, or else with the SHA-256 of
+// its content. Presigned links only allow GET, so the file is requested rather than its headers.
+// Like every request of a reader after its first one, it fails when the file is not the version first requested.
+func (httpTransport) Checksum(ctx context.Context, uri string, config Config) (string, error) {
+	fail := func(err error) (string, error) {
+		return "", fmt.Errorf("filereader: httpTransport: Checksum: %w", err)
+	}
+
+	file := newHTTPFile(uri, config)
+
+	response, err := file.get(ctx)
+	if err != nil {
+		return fail(err)
+	}
+
+	defer response.Body.Close()
+
+	if etag := strings.Trim(response.Header.Get("ETag"), `"`); etag != "" {
+		return etagPrefix + etag, nil
+	}
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, file.limit(response)); err != nil {
+		return fail(err)
+	}
+
+	return checksumPrefix + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// get requests the file, checking the status, the size and the type of the response before its body is read.
+func (file httpFile) get(ctx context.Context) (*http.Response, error) {
+	fail := func(err error) (*http.Response, error) {
+		return nil, fmt.Errorf("filereader: httpFile: get %s: %w", redactURL(file.fileURL), err)
+	}
+
+	parsedURL, err := url.Parse(file.fileURL)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return fail(ErrInvalidURI)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, file.fileURL, nil)
+	if err != nil {
+		return fail(fmt.Errorf("%w: %w", ErrInvalidURI, err))
+	}
+
+	switch {
+	case file.config.HTTP.BearerToken != "":
+		request.Header.Set("Authorization", "Bearer "+file.config.HTTP.BearerToken)
+	case file.config.HTTP.Username != "":
+		request.SetBasicAuth(file.config.HTTP.Username, file.config.HTTP.Password)
+	}
+
+	file.config.HTTP.version.condition(request)
+
+	response, err := file.client.Do(request)
+	if err != nil {
+		// The error of the client repeats the URL, signature of presigned links included.
+		return fail(fmt.Errorf("%w: %w", ErrDownloadFile, unwrapURLError(err)))
+	}
+
+	if errCheck := file.check(response); errCheck != nil {
+		response.Body.Close()
+		return fail(errCheck)
+	}
+
+	file.config.HTTP.version.pin(response)
+
+	return response, nil
+}
+
+func (file httpFile) check(response *http.Response) error {
+	switch {
+	case response.StatusCode == http.StatusNotFound:
+		return ErrFileNotFound
+	case response.StatusCode == http.StatusPreconditionFailed:
+		return fmt.Errorf("%w: the file changed since it was first requested", ErrDownloadFile)
+	case response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices:
+		return fmt.Errorf("%w: %s", ErrDownloadFile, response.Status)
+	}
+
+	if maxSize := file.maxBodySize(); maxSize >= 0 && response.ContentLength > maxSize {
+		return fmt.Errorf("%w: %d bytes, at most %d", ErrObjectTooLarge, response.ContentLength, maxSize)
+	}
+
+	contentType := response.Header.Get("Content-Type")
+	if contentType == "" {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !slices.Contains(file.contentTypes(), mediaType) {
+		return fmt.Errorf("%w: %s", ErrUnsupportedContentType, contentType)
+	}
+
+	return nil
+}
+
+// limit fails the body once it goes beyond the maximum size, as a server may not tell the size up front.
+func (file httpFile) limit(response *http.Response) io.Reader {
+	maxSize := file.maxBodySize()
+	if maxSize < 0 {
+		return response.Body
+	}
+
+	limited := io.LimitReader(response.Body, maxSize+1)
+	read := int64(0)
+
+	return readerFunc(func(p []byte) (int, error) {
+		n, err := limited.Read(p)
+		read += int64(n)
+		if read > maxSize {
+			return 0, fmt.Errorf("%w: more than %d bytes", ErrObjectTooLarge, maxSize)
+		}
+
+		return n, err
+	})
+}
+
+func (file httpFile) maxBodySize() int64 {
+	if file.config.HTTP.MaxBodySize == 0 {
+		return DefaultMaxObjectSize
+	}
+
+	return file.config.HTTP.MaxBodySize
+}
+
+func (file httpFile) contentTypes() []string {
+	if len(file.config.HTTP.ContentTypes) == 0 {
+		return DefaultContentTypes
+	}
+
+	return file.config.HTTP.ContentTypes
+}
+
+// RedactURI is the URI without the credentials it may hold, so that it can be stored: the query of http:// and
+// https:// URLs, which holds the signature of presigned links, and their user info. Other URIs are kept as they are.
+func RedactURI(uri string) string {
+	switch schemeOf(uri) {
+	case SchemeHTTP, SchemeHTTPS:
+		return redactURL(uri)
+	default:
+		return uri
+	}
+}
+
+// redactURL leaves the query out of the URL, as it holds the signature of presigned links.
+func redactURL(fileURL string) string {
+	parsedURL, err := url.Parse(fileURL)
+	if err != nil {
+		return "<invalid URL>"
+	}
+
+	parsedURL.RawQuery, parsedURL.User = "", nil
+
+	return parsedURL.String()
+}
+
+func unwrapURLError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+
+	return err
+}
@@ -0,0 +1,334 @@
+package filereader
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/govalues/decimal"
+
+	"stori/model"
+)
+
+// jsonRecordColumn is the only column of the quarantine file of JSON documents, holding each invalid record as is.
+const jsonRecordColumn = "record"
+
+// utf8BOM is the byte order mark some tools write at the start of UTF-8 files, which JSON decoders refuse.
+var utf8BOM = []byte("\xef\xbb\xbf") //nolint:gochecknoglobals // Read only
+
+// scanJSONRows yields the transactions of a JSON array of objects, or of newline-delimited JSON objects, one per
+// line. The fields of the objects are found by the names and aliases of the column mapping, case-insensitively.
+// Amounts are parsed from the text of their number, or from a string, so that they never go through a float64.
+func scanJSONRows(source io.Reader, config Config) iter.Seq2[Row, error] {
+	return func(yield func(Row, error) bool) {
+		fail := func(err error) {
+			yield(Row{}, fmt.Errorf("filereader: scanJSONRows: %w", err))
+		}
+
+		fields, err := newJSONFields(config.Columns.orDefault())
+		if err != nil {
+			fail(err)
+			return
+		}
+
+		content := bufio.NewReader(source)
+		first, skippedLines, err := firstByte(content)
+		if err != nil {
+			fail(fmt.Errorf("%w: %w", ErrInvalidFile, err))
+			return
+		}
+
+		records := scanNDJSONRecords(content, skippedLines)
+		if first == '[' {
+			records = scanJSONArrayRecords(content, skippedLines)
+		}
+
+		dates := newDateParser(config)
+		sequence := 0
+
+		for record, errRecord := range records {
+			if errRecord != nil {
+				fail(errRecord)
+				return
+			}
+
+			if !yield(fields.buildRow(record, sequence, dates), nil) {
+				return
+			}
+
+			sequence++
+		}
+	}
+}
+
+// jsonRecord is an element of a JSON array or a line of an NDJSON file, and the line it starts at.
+type jsonRecord struct {
+	raw  json.RawMessage
+	line int
+}
+
+// scanJSONArrayRecords yields the elements of the array the content holds. Syntax errors end the array, as the
+// elements after them cannot be told apart.
+func scanJSONArrayRecords(content io.Reader, skippedLines int) iter.Seq2[jsonRecord, error] {
+	return func(yield func(jsonRecord, error) bool) {
+		lines := &lineCounter{reader: content, line: skippedLines}
+		decoder := json.NewDecoder(lines)
+
+		if _, err := decoder.Token(); err != nil {
+			yield(jsonRecord{}, fmt.Errorf("%w: %w", ErrInvalidFile, err))
+			return
+		}
+
+		for decoder.More() {
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err != nil {
+				yield(jsonRecord{}, fmt.Errorf("%w: %w", ErrInvalidFile, err))
+				return
+			}
+
+			record := jsonRecord{raw: raw, line: lines.lineAt(decoder.InputOffset() - int64(len(raw)))}
+			if !yield(record, nil) {
+				return
+			}
+		}
+
+		if _, err := decoder.Token(); err != nil {
+			yield(jsonRecord{}, fmt.Errorf("%w: %w", ErrInvalidFile, err))
+		}
+	}
+}
+
+// scanNDJSONRecords yields the non-blank lines of the content. Lines that are not valid JSON are yielded as they are,
+// so that they are reported as invalid records while the others are still read.
+func scanNDJSONRecords(content *bufio.Reader, skippedLines int) iter.Seq2[jsonRecord, error] {
+	return func(yield func(jsonRecord, error) bool) {
+		for line := skippedLines + 1; ; line++ {
+			text, err := content.ReadBytes('\n')
+			if err != nil && !errors.Is(err, io.EOF) {
+				yield(jsonRecord{}, fmt.Errorf("%w: %w", ErrInvalidFile, err))
+				return
+			}
+
+			if trimmed := bytes.TrimSpace(text); len(trimmed) > 0 {
+				if !yield(jsonRecord{raw: trimmed, line: line}, nil) {
+					return
+				}
+			}
+
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// firstByte is the first byte of the content that is not white space, without reading it, and the number of lines
+// of white space skipped before it.
+func firstByte(content *bufio.Reader) (byte, int, error) {
+	if head, _ := content.Peek(len(utf8BOM)); bytes.Equal(head, utf8BOM) {
+		_, _ = content.Discard(len(utf8BOM))
+	}
+
+	skippedLines := 0
+	for {
+		head, err := content.Peek(1)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if !isJSONSpace(head[0]) {
+			return head[0], skippedLines, nil
+		}
+
+		if head[0] == '\n' {
+			skippedLines++
+		}
+
+		_, _ = content.ReadByte()
+	}
+}
+
+func isJSONSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+// sniffJSON recognizes JSON arrays and NDJSON objects by their first character.
+func sniffJSON(head []byte) bool {
+	head = bytes.TrimLeft(bytes.TrimPrefix(head, utf8BOM), " \t\r\n")
+
+	return len(head) > 0 && (head[0] == '[' || head[0] == '{')
+}
+
+// lineCounter tells the line of the offsets of what was read from it, asked in increasing order. It only keeps what
+// was read beyond the last offset asked.
+type lineCounter struct {
+	reader  io.Reader
+	pending []byte
+	offset  int64
+	line    int
+}
+
+func (counter *lineCounter) Read(p []byte) (int, error) {
+	n, err := counter.reader.Read(p)
+	counter.pending = append(counter.pending, p[:n]...)
+
+	return n, err
+}
+
+func (counter *lineCounter) lineAt(offset int64) int {
+	consumed := min(max(offset-counter.offset, 0), int64(len(counter.pending)))
+	counter.line += bytes.Count(counter.pending[:consumed], []byte("\n"))
+	counter.pending = append(counter.pending[:0], counter.pending[consumed:]...)
+	counter.offset += consumed
+
+	return counter.line + 1
+}
+
+// jsonFields are the columns of the mapping a record is read with.
+type jsonFields struct {
+	mapping ColumnMapping
+}
+
+// newJSONFields checks the mapping names the fields of the records, as they have no position.
+func newJSONFields(mapping ColumnMapping) (jsonFields, error) {
+	named := func(column Column) bool {
+		return column.Name != "" || len(column.Aliases) > 0
+	}
+
+	if !named(mapping.Date) || !named(mapping.Amount) || (!mapping.ID.isZero() && !named(mapping.ID)) ||
+		(!mapping.Account.isZero() && !named(mapping.Account)) {
+		return jsonFields{}, fmt.Errorf("filereader: newJSONFields: %w: JSON fields are found by name",
+			ErrInvalidMapping)
+	}
+
+	return jsonFields{mapping: mapping}, nil
+}
+
+func (fields jsonFields) buildRow(record jsonRecord, sequence int, dates *dateParser) Row {
+	row := Row{Line: record.line, Header: []string{jsonRecordColumn}, Fields: []string{string(record.raw)}}
+	invalid := func(column, value string, err error) {
+		row.Errors = append(row.Errors, &RowError{Line: record.line, Column: column, Value: value, Err: err})
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(record.raw, &object); err != nil || object == nil {
+		invalid("", string(record.raw), fmt.Errorf("%w: not a JSON object", ErrInvalidFile))
+		return row
+	}
+
+	id, ref, refName := sequence, "", ""
+	if !fields.mapping.ID.isZero() {
+		name, value := lookupJSON(object, fields.mapping.ID, idColumn)
+
+		var err error
+		if id, ref, err = parseID(value, sequence); err != nil {
+			invalid(name, value, err)
+		}
+		refName = strings.ToLower(name)
+	}
+
+	dateName, dateValue := lookupJSON(object, fields.mapping.Date, dateColumn)
+	date, err := parseJSONDate(dates, object[dateName], dateValue)
+	if err != nil {
+		invalid(dateName, dateValue, err)
+	}
+
+	amountName, amountValue := lookupJSON(object, fields.mapping.Amount, amountColumn)
+	amount, err := decimal.Parse(strings.TrimSpace(amountValue))
+	if err != nil {
+		invalid(amountName, amountValue, ErrInvalidAmount)
+	}
+
+	var account string
+	if !fields.mapping.Account.isZero() {
+		name, value := lookupJSON(object, fields.mapping.Account, accountColumn)
+		if account = strings.TrimSpace(value); account == "" {
+			invalid(name, value, ErrInvalidAccount)
+		}
+	}
+
+	var attributes map[string]string
+	for _, kept := range fields.mapping.Keep {
+		if name, value := lookupJSON(object, Column{Name: kept}, kept); object[name] != nil {
+			if attributes == nil {
+				attributes = make(map[string]string, len(fields.mapping.Keep))
+			}
+			attributes[kept] = value
+		}
+	}
+
+	if ref != "" {
+		if attributes == nil {
+			attributes = make(map[string]string, 1)
+		}
+		attributes[refName] = ref
+	}
+
+	row.Transaction = model.Transaction{
+		ID:         id,
+		Date:       date,
+		Amount:     amount,
+		Account:    account,
+		Attributes: attributes,
+	}
+
+	return row
+}
+
+// lookupJSON finds the field of the column in the object, trying its name first and then its aliases. It returns the
+// key of the field, or the fallback when it is missing, and its value as text: strings unquoted, the JSON of any
+// other value as is, e.g. the digits of a number, and nothing for null.
+func lookupJSON(object map[string]json.RawMessage, column Column, fallback string) (string, string) {
+	for _, name := range append([]string{column.Name}, column.Aliases...) {
+		if name == "" {
+			continue
+		}
+
+		if raw, ok := object[name]; ok {
+			return name, jsonText(raw)
+		}
+
+		for key, raw := range object {
+			if strings.EqualFold(key, name) {
+				return key, jsonText(raw)
+			}
+		}
+	}
+
+	return fallback, ""
+}
+
+func jsonText(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+
+	if string(raw) == "null" {
+		return ""
+	}
+
+	return string(raw)
+}
+
+// parseJSONDate accepts the ISO 8601 dates JSON documents usually hold, e.g. 2024-07-15 or 2024-07-15T10:00:00Z,
+// besides those of CSV files. Dates must be strings.
+func parseJSONDate(dates *dateParser, raw json.RawMessage, value string) (time.Time, error) {
+	if len(raw) == 0 || raw[0] != '"' {
+		return time.Time{}, fmt.Errorf("filereader: parseJSONDate %s: %w", value, ErrInvalidDateFormat)
+	}
+
+	for _, layout := range []string{time.DateOnly, time.RFC3339} {
+		if date, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return dates.dated(date), nil
+		}
+	}
+
+	return dates.parseDate(strings.TrimSpace(value))
+}
@@ -0,0 +1,190 @@
+package filereader_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stori/adapters/filereader"
+	"stori/test"
+)
+
+func TestReadTransactionsFromJSON_SameAsCSV(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		uri  string
+	}{
+		{name: "When JSON array", uri: "testdata/several_transactions.json"},
+		{name: "When NDJSON", uri: "testdata/several_transactions.ndjson"},
+		{name: "When sniffed", uri: writeTemp(t, "export", readTestdata(t, "several_transactions.ndjson"))},
+		{name: "When byte order mark", uri: writeTemp(t, "export.json",
+			append([]byte("\xef\xbb\xbf"), readTestdata(t, "several_transactions.json")...))},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			sut, err := filereader.NewReader(tc.uri, filereader.Config{})
+			require.NoError(t, err)
+
+			// Act
+			transactions, err := sut.ReadTransactions(context.Background())
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, localTransactions(t, "several_transactions.csv"), transactions)
+		})
+	}
+}
+
+func TestReadTransactionsFromJSON_WhenFieldsAreMapped_Success(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := newReader(t, "testdata/partner_transactions.json", filereader.Config{
+		Columns: filereader.ColumnMapping{
+			ID:      filereader.ParseColumn("id|ref"),
+			Date:    filereader.ParseColumn("date|fecha"),
+			Amount:  filereader.ParseColumn("amount|monto"),
+			Account: filereader.ParseColumn("account|customer"),
+			Keep:    []string{"description"},
+		},
+	})
+
+	// Act
+	transactions, err := sut.ReadTransactions(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+
+	assert.Equal(t, 17, transactions[0].ID)
+	assert.Equal(t, time.Date(2024, time.July, 15, 0, 0, 0, 0, time.UTC), transactions[0].Date)
+	assert.Equal(t, decimal.MustParse("12345678901234567.89"), transactions[0].Amount)
+	assert.Equal(t, "ACC-1", transactions[0].Account)
+	assert.Equal(t, map[string]string{"description": "Payroll"}, transactions[0].Attributes)
+
+	assert.Equal(t, 18, transactions[1].ID)
+	assert.Equal(t, time.Date(2024, time.July, 16, 10, 30, 0, 0, time.UTC), transactions[1].Date)
+	assert.Equal(t, "9007199254740993.01", transactions[1].Amount.String(), "numbers are not parsed as float64")
+	assert.Equal(t, "ACC-2", transactions[1].Account)
+	assert.Nil(t, transactions[1].Attributes)
+}
+
+func TestValidateJSON_WhenSeveralInvalidRecords_ReportsAll(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut, err := filereader.NewReader("testdata/several_invalid_rows.ndjson", filereader.Config{})
+	require.NoError(t, err)
+
+	// Act
+	report, err := sut.Validate(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 5, report.Rows)
+	require.Len(t, report.Errors, 5)
+
+	expected := []struct {
+		line   int
+		column string
+		err    error
+	}{
+		{line: 2, column: "transaction", err: filereader.ErrInvalidAmount},
+		{line: 3, column: "", err: filereader.ErrInvalidFile},
+		{line: 4, column: "id", err: filereader.ErrInvalidID},
+		{line: 4, column: "date", err: filereader.ErrInvalidDateFormat},
+		{line: 5, column: "date", err: filereader.ErrInvalidDateFormat},
+	}
+	for i, rowErr := range report.Errors {
+		assert.Equal(t, expected[i].line, rowErr.Line)
+		assert.Equal(t, expected[i].column, rowErr.Column)
+		assert.ErrorIs(t, rowErr, expected[i].err)
+	}
+}
+
+func TestReadTransactionsFromJSON_WhenFileIsInvalid_Error(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		content     string
+		config      filereader.Config
+		expectedErr error
+	}{
+		{
+			name:        "When array is not terminated",
+			content:     `[{"id": 0, "date": "7/15", "transaction": 60.5}`,
+			expectedErr: filereader.ErrInvalidFile,
+		},
+		{
+			name:        "When array has a syntax error",
+			content:     `[{"id": 0, "date": "7/15", "transaction": 60.5}, {"id": 1,]`,
+			expectedErr: filereader.ErrInvalidFile,
+		},
+		{
+			name:        "When amount is missing",
+			content:     `{"id": 0, "date": "7/15"}`,
+			expectedErr: filereader.ErrInvalidAmount,
+		},
+		{
+			name:    "When fields are mapped by position",
+			content: `{"id": 0, "date": "7/15", "transaction": 60.5}`,
+			config: filereader.Config{Columns: filereader.ColumnMapping{
+				Date:   filereader.Column{Position: 2},
+				Amount: filereader.Column{Position: 3},
+			}},
+			expectedErr: filereader.ErrInvalidMapping,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			sut, err := filereader.NewReader(writeTemp(t, "transactions.json", []byte(tc.content)), tc.config)
+			require.NoError(t, err)
+
+			// Act
+			_, err = sut.ReadTransactions(context.Background())
+
+			// Assert
+			require.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestValidateJSON_WhenArraySpansLines_LinesOfRecords(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	content := "\n[\n  {\"id\": 0, \"date\": \"7/15\", \"transaction\": 1},\n  {\n    \"id\": 1,\n" +
+		"    \"date\": \"7/16\", \"transaction\": \"abc\"\n  },\n" +
+		"  {\"id\": 2, \"date\": \"7/17\", \"transaction\": true}\n]\n"
+	sut, err := filereader.NewReader(writeTemp(t, "transactions.json", []byte(content)), filereader.Config{})
+	require.NoError(t, err)
+
+	// Act
+	report, err := sut.Validate(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, report.Errors, 2)
+	assert.Equal(t, 4, report.Errors[0].Line)
+	assert.Equal(t, 8, report.Errors[1].Line)
+}
@@ -1,6 +1,7 @@
 package filereader_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -17,10 +18,10 @@ func TestReadTransactions_WhenFileDoesNotExist_Error(t *testing.T) {
 	t.Parallel()
 
 	// Arrange
-	sut := filereader.NewLocalReader("testdata/non-existent-file.csv")
+	sut := newReader(t, "testdata/non-existent-file.csv", filereader.Config{})
 
 	// Act
-	_, err := sut.ReadTransactions()
+	_, err := sut.ReadTransactions(context.Background())
 
 	// Assert
 	require.Error(t, err)
@@ -32,10 +33,10 @@ func TestReadTransactions_WhenSingleTransaction_Success(t *testing.T) {
 	t.Parallel()
 
 	// Arrange
-	sut := filereader.NewLocalReader("testdata/single_transaction.csv")
+	sut := newReader(t, "testdata/single_transaction.csv", filereader.Config{})
 
 	// Act
-	transactions, err := sut.ReadTransactions()
+	transactions, err := sut.ReadTransactions(context.Background())
 
 	// Assert
 	require.NoError(t, err)
@@ -50,10 +51,10 @@ func TestReadTransactions_WhenSeveralTransactions_Success(t *testing.T) {
 	t.Parallel()
 
 	// Arrange
-	sut := filereader.NewLocalReader("testdata/several_transactions.csv")
+	sut := newReader(t, "testdata/several_transactions.csv", filereader.Config{})
 
 	// Act
-	transactions, err := sut.ReadTransactions()
+	transactions, err := sut.ReadTransactions(context.Background())
 
 	// Assert
 	require.NoError(t, err)
@@ -117,10 +118,10 @@ func TestReadTransactions_WhenFileIsInvalid_Error(t *testing.T) {
 			t.Parallel()
 
 			// Arrange
-			sut := filereader.NewLocalReader(tc.filename)
+			sut := newReader(t, tc.filename, filereader.Config{})
 
 			// Act
-			_, err := sut.ReadTransactions()
+			_, err := sut.ReadTransactions(context.Background())
 
 			// Assert
 			require.Error(t, err)
@@ -128,3 +129,159 @@ func TestReadTransactions_WhenFileIsInvalid_Error(t *testing.T) {
 		})
 	}
 }
+
+func TestStreamTransactions_WhenSeveralTransactions_YieldsEachRow(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := newReader(t, "testdata/several_transactions.csv", filereader.Config{})
+
+	// Act
+	var ids []int
+	for transaction, err := range sut.StreamTransactions(context.Background()) {
+		require.NoError(t, err)
+		ids = append(ids, transaction.ID)
+	}
+
+	// Assert
+	assert.Equal(t, []int{0, 1, 2, 3}, ids)
+}
+
+func TestStreamTransactions_WhenStoppedEarly_DoesNotYieldMore(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := newReader(t, "testdata/several_transactions.csv", filereader.Config{})
+
+	// Act
+	var ids []int
+	for transaction, err := range sut.StreamTransactions(context.Background()) {
+		require.NoError(t, err)
+		ids = append(ids, transaction.ID)
+		if len(ids) == 2 {
+			break
+		}
+	}
+
+	// Assert
+	assert.Equal(t, []int{0, 1}, ids)
+}
+
+func TestStreamTransactions_WhenInvalidRow_YieldsError(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := newReader(t, "testdata/invalid_amount.csv", filereader.Config{})
+
+	// Act
+	var errs []error
+	for _, err := range sut.StreamTransactions(context.Background()) {
+		errs = append(errs, err)
+	}
+
+	// Assert
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], filereader.ErrInvalidAmount)
+}
+
+func TestStreamTransactions_WhenContextCancelled_StopsWithItsError(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := newReader(t, "testdata/several_transactions.csv", filereader.Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Act
+	var ids []int
+	var errs []error
+	for transaction, err := range sut.StreamTransactions(ctx) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		ids = append(ids, transaction.ID)
+		cancel()
+	}
+
+	// Assert
+	assert.Equal(t, []int{0}, ids)
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], context.Canceled)
+}
+
+func TestReadTransactions_WhenYearlessDates_YearAssigned(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		config        filereader.Config
+		expectedYears []int
+	}{
+		{
+			name:          "Fixed default year",
+			config:        filereader.Config{DefaultYear: 2023},
+			expectedYears: []int{2023, 2023, 2023, 2023},
+		},
+		{
+			name:          "Rollover from default year",
+			config:        filereader.Config{DefaultYear: 2023, YearInference: filereader.YearRollover},
+			expectedYears: []int{2023, 2024, 2024, 2024},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			sut := newReader(t, "testdata/spanning_years.csv", tc.config)
+
+			// Act
+			transactions, err := sut.ReadTransactions(context.Background())
+
+			// Assert
+			require.NoError(t, err)
+			require.Len(t, transactions, len(tc.expectedYears))
+			for i, year := range tc.expectedYears {
+				assert.Equal(t, year, transactions[i].Date.Year())
+			}
+		})
+	}
+}
+
+func TestReadTransactions_WhenLeapDayInNonLeapYear_Error(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := newReader(t, "testdata/leap_day.csv", filereader.Config{DefaultYear: 2023})
+
+	// Act
+	_, err := sut.ReadTransactions(context.Background())
+
+	// Assert
+	require.Error(t, err)
+	assert.ErrorIs(t, err, filereader.ErrInvalidDateFormat)
+}
+
+func TestChecksum_WhenFileExists_SHA256OfContent(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := newReader(t, "testdata/single_transaction.csv", filereader.Config{})
+
+	// Act
+	checksum, err := sut.Checksum(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:bd9c41a8b53b44a2e52fa1e0221b1c3f3ea5cfb08420d4e75fdad78866749b90", checksum)
+}
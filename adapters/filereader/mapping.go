@@ -0,0 +1,237 @@
+package filereader
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+type (
+	// ColumnMapping describes where the fields of a transaction are found in a CSV file, or by name only in the
+	// objects of a JSON file. Its zero value is the default layout: a comma separated file with an
+	// "id,date,transaction" header.
+	ColumnMapping struct {
+		Delimiter rune
+		// NoHeader tells the file starts with data. Columns are then found by position only.
+		NoHeader bool
+		// ID is optional. Without it, transactions are numbered in order of appearance, starting at 0.
+		ID     Column
+		Date   Column
+		Amount Column
+		// Account is optional. It is set in files consolidating the transactions of several accounts.
+		Account Column
+		// Keep lists the extra columns whose values are kept in the attributes of each transaction.
+		// Any other extra column is ignored.
+		Keep []string
+	}
+
+	// Column is found in the header by its name or any of its aliases, case-insensitively,
+	// and otherwise by its 1-based position.
+	Column struct {
+		Name     string
+		Aliases  []string
+		Position int
+	}
+
+	columnMappingFile struct {
+		Delimiter string     `json:"delimiter"`
+		NoHeader  bool       `json:"noHeader"`
+		ID        columnFile `json:"id"`
+		Date      columnFile `json:"date"`
+		Amount    columnFile `json:"amount"`
+		Account   columnFile `json:"account"`
+		Keep      []string   `json:"keep"`
+	}
+
+	columnFile struct {
+		Name     string   `json:"name"`
+		Aliases  []string `json:"aliases"`
+		Position int      `json:"position"`
+	}
+
+	// columnLayout is the mapping resolved against the header of a file. Indexes are 0-based and -1 when absent.
+	columnLayout struct {
+		header  []string
+		id      int
+		date    int
+		amount  int
+		account int
+		kept    map[string]int
+	}
+)
+
+const (
+	defaultDelimiter = ','
+
+	idColumn      = "id"
+	dateColumn    = "date"
+	amountColumn  = "transaction"
+	accountColumn = "account"
+)
+
+func DefaultColumnMapping() ColumnMapping {
+	return ColumnMapping{
+		Delimiter: defaultDelimiter,
+		ID:        Column{Name: idColumn},
+		Date:      Column{Name: dateColumn},
+		Amount:    Column{Name: amountColumn},
+	}
+}
+
+// LoadColumnMapping reads a mapping from a JSON file like:
+//
+//	{"delimiter": ";", "id": {"name": "ref"}, "date": {"name": "date", "aliases": ["fecha"]},
+//	 "amount": {"name": "amount", "position": 2}, "account": {"name": "customer"}, "keep": ["description"]}
+func LoadColumnMapping(path string) (ColumnMapping, error) {
+	fail := func(err error) (ColumnMapping, error) {
+		return ColumnMapping{}, fmt.Errorf("filereader: LoadColumnMapping: %w", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fail(fmt.Errorf("%w: %w", ErrInvalidMapping, err))
+	}
+
+	var file columnMappingFile
+	if err = json.Unmarshal(content, &file); err != nil {
+		return fail(fmt.Errorf("%w: %w", ErrInvalidMapping, err))
+	}
+
+	delimiter, err := ParseDelimiter(file.Delimiter)
+	if err != nil {
+		return fail(err)
+	}
+
+	return ColumnMapping{
+		Delimiter: delimiter,
+		NoHeader:  file.NoHeader,
+		ID:        Column(file.ID),
+		Date:      Column(file.Date),
+		Amount:    Column(file.Amount),
+		Account:   Column(file.Account),
+		Keep:      file.Keep,
+	}, nil
+}
+
+// ParseDelimiter accepts a single character, or "\t" for tab separated files. An empty string is the default comma.
+func ParseDelimiter(delimiter string) (rune, error) {
+	if delimiter == "" {
+		return defaultDelimiter, nil
+	}
+
+	if delimiter == `\t` {
+		return '\t', nil
+	}
+
+	if utf8.RuneCountInString(delimiter) != 1 {
+		return 0, fmt.Errorf("filereader: ParseDelimiter %q: %w", delimiter, ErrInvalidMapping)
+	}
+
+	r, _ := utf8.DecodeRuneInString(delimiter)
+
+	return r, nil
+}
+
+// ParseColumn accepts a column name followed by its aliases, separated by "|", e.g. "amount|monto".
+func ParseColumn(names string) Column {
+	if names == "" {
+		return Column{}
+	}
+
+	parts := strings.Split(names, "|")
+
+	return Column{Name: parts[0], Aliases: parts[1:]}
+}
+
+func (mapping ColumnMapping) orDefault() ColumnMapping {
+	if mapping.isZero() {
+		return DefaultColumnMapping()
+	}
+
+	if mapping.Delimiter == 0 {
+		mapping.Delimiter = defaultDelimiter
+	}
+
+	return mapping
+}
+
+func (mapping ColumnMapping) isZero() bool {
+	return mapping.Delimiter == 0 && !mapping.NoHeader && len(mapping.Keep) == 0 &&
+		mapping.ID.isZero() && mapping.Date.isZero() && mapping.Amount.isZero() && mapping.Account.isZero()
+}
+
+func (column Column) isZero() bool {
+	return column.Name == "" && len(column.Aliases) == 0 && column.Position == 0
+}
+
+func (column Column) matches(headerName string) bool {
+	name := strings.TrimSpace(headerName)
+
+	return strings.EqualFold(name, column.Name) ||
+		slices.ContainsFunc(column.Aliases, func(alias string) bool { return strings.EqualFold(name, alias) })
+}
+
+// index finds the column in the header, or falls back to its position. It is -1 when absent.
+func (column Column) index(header []string) int {
+	if header != nil && (column.Name != "" || len(column.Aliases) > 0) {
+		if i := slices.IndexFunc(header, column.matches); i >= 0 {
+			return i
+		}
+	}
+
+	return column.Position - 1
+}
+
+// resolve locates the columns of the mapping, header being nil when the file has none.
+func (mapping ColumnMapping) resolve(header []string) (columnLayout, error) {
+	fail := func(err error) (columnLayout, error) {
+		return columnLayout{}, fmt.Errorf("filereader: ColumnMapping: resolve: %w", err)
+	}
+
+	layout := columnLayout{
+		header:  slices.Clone(header),
+		id:      mapping.ID.index(header),
+		date:    mapping.Date.index(header),
+		amount:  mapping.Amount.index(header),
+		account: mapping.Account.index(header),
+		kept:    make(map[string]int),
+	}
+
+	if layout.date < 0 || layout.amount < 0 || (layout.id < 0 && !mapping.ID.isZero()) ||
+		(layout.account < 0 && !mapping.Account.isZero()) ||
+		(header != nil && max(layout.id, layout.date, layout.amount, layout.account) >= len(header)) {
+		if header == nil {
+			return fail(ErrInvalidMapping)
+		}
+
+		return fail(ErrInvalidHeader)
+	}
+
+	for _, name := range mapping.Keep {
+		if i := (Column{Name: name}).index(header); i >= 0 {
+			layout.kept[name] = i
+		}
+	}
+
+	return layout, nil
+}
+
+// columnName is the name of the column in the header, or the fallback when the file has none.
+func (layout columnLayout) columnName(index int, fallback string) string {
+	if index < len(layout.header) {
+		return strings.TrimSpace(layout.header[index])
+	}
+
+	return fallback
+}
+
+func (layout columnLayout) field(fields []string, index int) (string, bool) {
+	if index < 0 || index >= len(fields) {
+		return "", false
+	}
+
+	return fields[index], true
+}
@@ -0,0 +1,234 @@
+package filereader_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stori/adapters/filereader"
+	"stori/test"
+)
+
+func TestReadTransactions_WhenMappingFromFile_Success(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	mapping, err := filereader.LoadColumnMapping("testdata/partner_mapping.json")
+	require.NoError(t, err)
+
+	sut := newReader(t, "testdata/partner_semicolon.csv", filereader.Config{Columns: mapping})
+
+	// Act
+	transactions, err := sut.ReadTransactions(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+
+	assert.Equal(t, 0, transactions[0].ID)
+	assert.Equal(t, decimal.MustParse("60.5"), transactions[0].Amount)
+	assert.Equal(t, map[string]string{"description": "Coffee", "merchant": "Cafe Uno"}, transactions[0].Attributes)
+
+	assert.Equal(t, 1, transactions[1].ID)
+	assert.Equal(t, decimal.MustParse("-10.3"), transactions[1].Amount)
+	assert.Equal(t, map[string]string{"description": "Books", "merchant": "Libros SA"}, transactions[1].Attributes)
+}
+
+func TestReadTransactions_WhenIDsAreReferences_KeptAsAttributes(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	mapping, err := filereader.LoadColumnMapping("testdata/partner_mapping.json")
+	require.NoError(t, err)
+
+	mapping.ID = filereader.ParseColumn("ref")
+	sut := newReader(t, "testdata/partner_semicolon.csv", filereader.Config{Columns: mapping})
+
+	// Act
+	transactions, err := sut.ReadTransactions(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+
+	assert.Equal(t, 0, transactions[0].ID)
+	assert.Equal(t, map[string]string{"ref": "A-1", "description": "Coffee", "merchant": "Cafe Uno"},
+		transactions[0].Attributes)
+
+	assert.Equal(t, 1, transactions[1].ID)
+	assert.Equal(t, map[string]string{"ref": "A-2", "description": "Books", "merchant": "Libros SA"},
+		transactions[1].Attributes)
+}
+
+func TestReadTransactions_WhenHeaderAliasesInAnyOrder_Success(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := newReader(t, "testdata/aliased_header.csv", filereader.Config{
+		Columns: filereader.ColumnMapping{
+			ID:     filereader.ParseColumn("id"),
+			Date:   filereader.ParseColumn("date|fecha"),
+			Amount: filereader.ParseColumn("transaction|monto"),
+		},
+	})
+
+	// Act
+	transactions, err := sut.ReadTransactions(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, 7, transactions[0].ID)
+	assert.Equal(t, decimal.MustParse("60.5"), transactions[0].Amount)
+	assert.Equal(t, 9, transactions[1].ID)
+	assert.Equal(t, decimal.MustParse("-20.46"), transactions[1].Amount)
+	assert.Nil(t, transactions[1].Attributes)
+}
+
+func TestReadTransactions_WhenNoHeaderAndPositions_Success(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := newReader(t, "testdata/no_header.csv", filereader.Config{
+		Columns: filereader.ColumnMapping{
+			Delimiter: '|',
+			NoHeader:  true,
+			Date:      filereader.Column{Position: 1},
+			Amount:    filereader.Column{Position: 2},
+		},
+	})
+
+	// Act
+	transactions, err := sut.ReadTransactions(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, decimal.MustParse("60.5"), transactions[0].Amount)
+	assert.Equal(t, decimal.MustParse("-20.46"), transactions[1].Amount)
+}
+
+func TestReadTransactions_WhenMappedColumnMissing_Error(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		filename      string
+		mapping       filereader.ColumnMapping
+		expectedError error
+	}{
+		{
+			name:          "When column is not in the header",
+			filename:      "testdata/partner_semicolon.csv",
+			mapping:       filereader.ColumnMapping{Delimiter: ';', Date: filereader.ParseColumn("date")},
+			expectedError: filereader.ErrInvalidHeader,
+		},
+		{
+			name:     "When file has no header and no positions",
+			filename: "testdata/no_header.csv",
+			mapping: filereader.ColumnMapping{
+				Delimiter: '|',
+				NoHeader:  true,
+				Date:      filereader.ParseColumn("date"),
+				Amount:    filereader.ParseColumn("amount"),
+			},
+			expectedError: filereader.ErrInvalidMapping,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			sut := newReader(t, tc.filename, filereader.Config{Columns: tc.mapping})
+
+			// Act
+			_, err := sut.ReadTransactions(context.Background())
+
+			// Assert
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestParseDelimiter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		delimiter string
+		expected  rune
+		valid     bool
+	}{
+		{delimiter: "", expected: ',', valid: true},
+		{delimiter: ";", expected: ';', valid: true},
+		{delimiter: `\t`, expected: '\t', valid: true},
+		{delimiter: ";;", valid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.delimiter, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			delimiter, err := filereader.ParseDelimiter(tc.delimiter)
+
+			// Assert
+			if !tc.valid {
+				require.ErrorIs(t, err, filereader.ErrInvalidMapping)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, delimiter)
+		})
+	}
+}
+
+func TestReadTransactions_WhenAccountColumn_Success(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	mapping := filereader.DefaultColumnMapping()
+	mapping.Account = filereader.ParseColumn("account|customer")
+
+	sut := newReader(t, "testdata/consolidated.csv", filereader.Config{Columns: mapping})
+
+	// Act
+	transactions, err := sut.ReadTransactions(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, transactions, 3)
+	assert.Equal(t, "ACC-1", transactions[0].Account)
+	assert.Equal(t, "ACC-2", transactions[1].Account)
+	assert.Equal(t, "ACC-1", transactions[2].Account)
+}
+
+func TestReadTransactions_WhenAccountIsEmpty_Error(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	mapping := filereader.DefaultColumnMapping()
+	mapping.Account = filereader.ParseColumn("account")
+
+	sut := newReader(t, "testdata/missing_account.csv", filereader.Config{Columns: mapping})
+
+	// Act
+	_, err := sut.ReadTransactions(context.Background())
+
+	// Assert
+	require.Error(t, err)
+	assert.ErrorIs(t, err, filereader.ErrInvalidAccount)
+}
@@ -0,0 +1,173 @@
+package filereader_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+
+	"stori/adapters/filereader"
+)
+
+const (
+	minioBucket   = "statements"
+	minioProfile  = "minio"
+	minioUser     = "minio"
+	minioPassword = "minio-secret"
+)
+
+// The MinIO container is only started by the first S3 test, so that the other tests do not need Docker.
+var minio struct { //nolint:gochecknoglobals // Shared by the S3 tests
+	once     sync.Once
+	resource *dockertest.Resource
+	pool     *dockertest.Pool
+	config   filereader.S3Config
+	err      error
+}
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	// The credentials of MinIO are read from a profile, as the default credential chain does with real buckets.
+	credentialsDir, err := os.MkdirTemp("", "minio")
+	if err != nil {
+		log.Fatalf("Could not create credentials dir: %s", err)
+	}
+
+	credentialsPath := filepath.Join(credentialsDir, "credentials")
+	content := fmt.Sprintf("[%s]\naws_access_key_id = %s\naws_secret_access_key = %s\n",
+		minioProfile, minioUser, minioPassword)
+	if errWrite := os.WriteFile(credentialsPath, []byte(content), 0o600); errWrite != nil {
+		log.Fatalf("Could not write credentials: %s", errWrite)
+	}
+
+	if errEnv := os.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsPath); errEnv != nil {
+		log.Fatalf("Could not set credentials file: %s", errEnv)
+	}
+
+	code := m.Run()
+
+	if minio.resource != nil {
+		if errPurge := minio.pool.Purge(minio.resource); errPurge != nil {
+			log.Printf("Could not purge resource: %s", errPurge)
+		}
+	}
+
+	_ = os.RemoveAll(credentialsDir)
+	os.Exit(code)
+}
+
+// minioS3 is the config of the S3 reader for a local MinIO holding the test data in minioBucket, under the file
+// names of the testdata folder.
+func minioS3(t *testing.T) filereader.S3Config {
+	t.Helper()
+
+	minio.once.Do(func() {
+		minio.config, minio.err = startMinIO()
+	})
+
+	if minio.err != nil {
+		t.Fatalf("Could not start MinIO: %s", minio.err)
+	}
+
+	return minio.config
+}
+
+func startMinIO() (filereader.S3Config, error) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		return filereader.S3Config{}, fmt.Errorf("could not construct pool: %w", err)
+	}
+
+	if err = pool.Client.Ping(); err != nil {
+		return filereader.S3Config{}, fmt.Errorf("could not connect to Docker: %w", err)
+	}
+
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
+		Repository: "minio/minio",
+		Tag:        "RELEASE.2024-01-16T16-07-38Z",
+		Cmd:        []string{"server", "/data"},
+		Env: []string{
+			"MINIO_ROOT_USER=" + minioUser,
+			"MINIO_ROOT_PASSWORD=" + minioPassword,
+		},
+	}, func(config *docker.HostConfig) {
+		config.AutoRemove = true
+		config.RestartPolicy = docker.RestartPolicy{Name: "no"}
+	})
+	if err != nil {
+		return filereader.S3Config{}, fmt.Errorf("could not start resource: %w", err)
+	}
+
+	minio.pool, minio.resource = pool, resource
+
+	if errExp := resource.Expire(120); errExp != nil {
+		return filereader.S3Config{}, fmt.Errorf("could not set expiry to MinIO container: %w", errExp)
+	}
+
+	config := filereader.S3Config{
+		Region:    "eu-west-1",
+		Profile:   minioProfile,
+		Endpoint:  "http://" + resource.GetHostPort("9000/tcp"),
+		PathStyle: true,
+	}
+
+	client, err := filereader.NewS3Client(config)
+	if err != nil {
+		return filereader.S3Config{}, err
+	}
+
+	pool.MaxWait = 120 * time.Second
+	if errRetry := pool.Retry(func() error { return createBucket(client) }); errRetry != nil {
+		return filereader.S3Config{}, fmt.Errorf("could not connect to MinIO: %w", errRetry)
+	}
+
+	return config, uploadTestData(client)
+}
+
+func createBucket(client *s3.S3) error {
+	_, err := client.CreateBucket(&s3.CreateBucketInput{Bucket: aws.String(minioBucket)})
+
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) && awsErr.Code() == s3.ErrCodeBucketAlreadyOwnedByYou {
+		return nil
+	}
+
+	return err
+}
+
+func uploadTestData(client *s3.S3) error {
+	paths, err := filepath.Glob("testdata/*.csv")
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		content, errRead := os.ReadFile(path)
+		if errRead != nil {
+			return errRead
+		}
+
+		if _, errPut := client.PutObject(&s3.PutObjectInput{
+			Bucket: aws.String(minioBucket),
+			Key:    aws.String(filepath.Base(path)),
+			Body:   bytes.NewReader(content),
+		}); errPut != nil {
+			return fmt.Errorf("could not upload %s: %w", path, errPut)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package filereader
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/govalues/decimal"
+
+	"stori/model"
+)
+
+const (
+	ofxFITID    = "FITID"
+	ofxDTPOSTED = "DTPOSTED"
+	ofxTRNAMT   = "TRNAMT"
+
+	ofxDateLayout     = "20060102"
+	ofxDateTimeLayout = "20060102150405"
+)
+
+type ofxTransaction struct {
+	line   int
+	fields map[string]string
+}
+
+// scanOFXRows yields a row for every STMTTRN of a bank statement in the OFX format, either 1.x SGML or 2.x XML. QFX
+// files are OFX files too. DTPOSTED is the date of the transaction, TRNAMT its amount, and FITID its ID when numeric.
+// FITID, TRNTYPE, NAME and MEMO are kept in the attributes of the transaction.
+// Leaf elements are read the same way whether they are closed (XML) or not (SGML).
+func scanOFXRows(source io.Reader) iter.Seq2[Row, error] {
+	return func(yield func(Row, error) bool) {
+		fail := func(err error) {
+			yield(Row{}, fmt.Errorf("filereader: scanOFXRows: %w", err))
+		}
+
+		reader := bufio.NewReader(source)
+		line := 1
+		readUntil := func(delim byte) (string, error) {
+			content, err := reader.ReadString(delim)
+			line += strings.Count(content, "\n")
+
+			return content, err
+		}
+
+		var isOFX bool
+		var current *ofxTransaction
+		var openLeaf string
+
+		for sequence := 0; ; {
+			text, err := readUntil('<')
+			if current != nil && openLeaf != "" {
+				current.fields[openLeaf] = html.UnescapeString(strings.TrimSpace(strings.TrimSuffix(text, "<")))
+			}
+			openLeaf = ""
+
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			tag, err := readUntil('>')
+			if err != nil {
+				fail(fmt.Errorf("%w: unterminated tag at line %d", ErrInvalidFile, line))
+				return
+			}
+
+			name := strings.ToUpper(strings.TrimSpace(strings.TrimSuffix(tag, ">")))
+			switch {
+			case strings.HasPrefix(name, "?"), strings.HasPrefix(name, "!"):
+				// Processing instructions, like the OFX 2.x headers, and comments carry no data.
+			case name == "OFX":
+				isOFX = true
+			case name == "STMTTRN":
+				current = &ofxTransaction{line: line, fields: make(map[string]string)}
+			case name == "/STMTTRN" && current != nil:
+				row := buildOFXRow(*current, sequence)
+				current = nil
+				sequence++
+
+				if !yield(row, nil) {
+					return
+				}
+			case !strings.HasPrefix(name, "/"):
+				openLeaf = name
+			}
+		}
+
+		if !isOFX {
+			fail(fmt.Errorf("%w: no OFX element found", ErrInvalidFile))
+			return
+		}
+
+		if current != nil {
+			fail(fmt.Errorf("%w: STMTTRN at line %d is not closed", ErrInvalidFile, current.line))
+		}
+	}
+}
+
+func buildOFXRow(transaction ofxTransaction, sequence int) Row {
+	fields := transaction.fields
+	row := Row{
+		Line:   transaction.line,
+		Header: []string{ofxFITID, ofxDTPOSTED, ofxTRNAMT},
+		Fields: []string{fields[ofxFITID], fields[ofxDTPOSTED], fields[ofxTRNAMT]},
+	}
+	invalid := func(column string, err error) {
+		row.Errors = append(row.Errors, &RowError{Line: transaction.line, Column: column, Value: fields[column], Err: err})
+	}
+
+	id, err := strconv.Atoi(fields[ofxFITID])
+	if err != nil {
+		id = sequence
+	}
+
+	date, err := parseOFXDate(fields[ofxDTPOSTED])
+	if err != nil {
+		invalid(ofxDTPOSTED, err)
+	}
+
+	// Some banks use a comma as decimal separator, which the OFX specification allows.
+	amount, err := decimal.Parse(strings.Replace(fields[ofxTRNAMT], ",", ".", 1))
+	if err != nil {
+		invalid(ofxTRNAMT, ErrInvalidAmount)
+	}
+
+	attributes := make(map[string]string)
+	for _, name := range []string{ofxFITID, "TRNTYPE", "NAME", "MEMO"} {
+		if value, ok := fields[name]; ok {
+			attributes[strings.ToLower(name)] = value
+		}
+	}
+
+	row.Transaction = model.Transaction{
+		ID:         id,
+		Date:       date,
+		Amount:     amount,
+		Attributes: attributes,
+	}
+
+	return row
+}
+
+// parseOFXDate reads dates like 20240115, 20240115120000 or 20240115120000.000[-5:EST].
+// Fractional seconds and the time zone are ignored.
+func parseOFXDate(datestr string) (time.Time, error) {
+	layout := ofxDateLayout
+	if len(datestr) >= len(ofxDateTimeLayout) {
+		layout = ofxDateTimeLayout
+	}
+
+	if len(datestr) < len(layout) {
+		return time.Time{}, fmt.Errorf("filereader: parseOFXDate %s: %w", datestr, ErrInvalidDateFormat)
+	}
+
+	date, err := time.Parse(layout, datestr[:len(layout)])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("filereader: parseOFXDate %s: %w", datestr, ErrInvalidDateFormat)
+	}
+
+	return date, nil
+}
+
+// sniffOFX recognizes OFX documents by their header or their root element.
+func sniffOFX(head []byte) bool {
+	head = bytes.ToUpper(head)
+
+	return bytes.Contains(head, []byte("OFXHEADER")) || bytes.Contains(head, []byte("<OFX>")) ||
+		bytes.Contains(head, []byte("<?OFX"))
+}
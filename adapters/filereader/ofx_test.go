@@ -0,0 +1,174 @@
+package filereader_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stori/adapters/filereader"
+	"stori/test"
+)
+
+func TestReadTransactionsFromOFX_WhenSGML_Success(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := newReader(t, "testdata/statement_sgml.ofx", filereader.Config{})
+
+	// Act
+	transactions, err := sut.ReadTransactions(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+
+	assert.Equal(t, 1001, transactions[0].ID)
+	assert.Equal(t, time.Date(2024, time.July, 15, 12, 0, 0, 0, time.UTC), transactions[0].Date)
+	assert.Equal(t, decimal.MustParse("60.50"), transactions[0].Amount)
+	assert.Equal(t, "Payroll & Co", transactions[0].Attributes["name"])
+	assert.Equal(t, "CREDIT", transactions[0].Attributes["trntype"])
+
+	assert.Equal(t, 1, transactions[1].ID)
+	assert.Equal(t, time.Date(2024, time.July, 28, 0, 0, 0, 0, time.UTC), transactions[1].Date)
+	assert.Equal(t, decimal.MustParse("-10.30"), transactions[1].Amount)
+	assert.Equal(t, "A-1002", transactions[1].Attributes["fitid"])
+	assert.Equal(t, "Coffee", transactions[1].Attributes["memo"])
+}
+
+func TestReadTransactionsFromOFX_WhenXML_Success(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := newReader(t, "testdata/statement_xml.qfx", filereader.Config{})
+
+	// Act
+	transactions, err := sut.ReadTransactions(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+
+	assert.Equal(t, 2, transactions[0].ID)
+	assert.Equal(t, time.Date(2024, time.August, 2, 0, 0, 0, 0, time.UTC), transactions[0].Date)
+	assert.Equal(t, decimal.MustParse("-20.46"), transactions[0].Amount)
+
+	assert.Equal(t, 3, transactions[1].ID)
+	assert.Equal(t, time.Date(2024, time.August, 13, 0, 0, 0, 0, time.UTC), transactions[1].Date)
+	assert.Equal(t, decimal.MustParse("10"), transactions[1].Amount)
+}
+
+func TestReadTransactionsFromOFX_WhenFileIsInvalid_Error(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		filename      string
+		expectedError error
+	}{
+		{
+			name:          "When file does not exist",
+			filename:      "testdata/non-existent-file.ofx",
+			expectedError: filereader.ErrFileNotFound,
+		},
+		{
+			name:          "When file is empty",
+			filename:      "testdata/empty_file.csv",
+			expectedError: filereader.ErrFileIsEmpty,
+		},
+		{
+			name:          "When file is not OFX",
+			filename:      "testdata/several_transactions.csv",
+			expectedError: filereader.ErrInvalidFile,
+		},
+		{
+			name:          "When file has invalid date format",
+			filename:      "testdata/invalid_statement.ofx",
+			expectedError: filereader.ErrInvalidDateFormat,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			sut := newReader(t, tc.filename, filereader.Config{Format: filereader.FormatOFX})
+
+			// Act
+			_, err := sut.ReadTransactions(context.Background())
+
+			// Assert
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestValidateOFX_WhenSeveralInvalidTransactions_ReportsEveryError(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := newReader(t, "testdata/invalid_statement.ofx", filereader.Config{})
+
+	// Act
+	report, err := sut.Validate(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Rows)
+	require.Len(t, report.Errors, 2)
+
+	assert.Equal(t, 6, report.Errors[0].Line)
+	assert.Equal(t, "DTPOSTED", report.Errors[0].Column)
+	require.ErrorIs(t, report.Errors[0], filereader.ErrInvalidDateFormat)
+
+	assert.Equal(t, 11, report.Errors[1].Line)
+	assert.Equal(t, "TRNAMT", report.Errors[1].Column)
+	assert.Equal(t, "abc", report.Errors[1].Value)
+	require.ErrorIs(t, report.Errors[1], filereader.ErrInvalidAmount)
+}
+
+func TestReadTransactions_FormatDetected(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	testCases := []struct {
+		filename    string
+		expectedErr error
+	}{
+		{filename: "testdata/several_transactions.csv"},
+		{filename: "testdata/statement_sgml.ofx"},
+		{filename: "testdata/statement_xml.qfx"},
+		{filename: "testdata/statement_without_extension"},
+		{filename: "testdata/non-existent-file", expectedErr: filereader.ErrFileNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.filename, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			sut := newReader(t, tc.filename, filereader.Config{})
+
+			// Act
+			transactions, err := sut.ReadTransactions(context.Background())
+
+			// Assert
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotEmpty(t, transactions)
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package filereader
 
 import (
+	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
-	"os"
+	"io"
+	"iter"
 	"strconv"
 	"strings"
 	"time"
@@ -13,85 +16,303 @@ import (
 	"stori/model"
 )
 
-func readTransactions(file *os.File) ([]model.Transaction, error) {
-	fail := func(err error) ([]model.Transaction, error) {
-		return nil, fmt.Errorf("filereader: Local: readTransactions: %w", err)
+func collectTransactions(stream iter.Seq2[model.Transaction, error]) ([]model.Transaction, error) {
+	transactions := make([]model.Transaction, 0)
+	for transaction, err := range stream {
+		if err != nil {
+			return nil, err
+		}
+
+		transactions = append(transactions, transaction)
 	}
 
-	csvReader := csv.NewReader(file)
-	csvReader.FieldsPerRecord = numberOfColumns
+	return transactions, nil
+}
+
+// Row is a record of a file, as decoders yield them: the transaction it holds, or why it is invalid.
+type Row struct {
+	Transaction model.Transaction
+	// Line is where the record starts in the file, so that errors can point to it.
+	Line int
+	// Header names the Fields, which are written as is to the quarantine file when the row is invalid.
+	Header []string
+	Fields []string
+	// Errors make the row invalid when there is any, in which case Transaction is ignored.
+	Errors []*RowError
+}
 
-	data, err := csvReader.ReadAll()
-	if err != nil {
-		return fail(fmt.Errorf("%w: %w", ErrInvalidFile, err))
-	}
+func (row Row) isValid() bool {
+	return len(row.Errors) == 0
+}
 
-	if isNotValidHeader(data[0]) {
-		return fail(ErrInvalidHeader)
+func (row Row) err() error {
+	errs := make([]error, len(row.Errors))
+	for i, rowErr := range row.Errors {
+		errs[i] = rowErr
 	}
 
-	transactions := make([]model.Transaction, 0)
-	for _, row := range data[1:] {
-		transaction, trError := buildTransaction(row)
-		if trError != nil {
-			return fail(trError)
+	return errors.Join(errs...)
+}
+
+// streamTransactions yields the valid transactions of the scanned rows and handles invalid rows according to the
+// InvalidRowPolicy of the config. It stops with the error of the context once it is done.
+func streamTransactions(
+	ctx context.Context, rows iter.Seq2[Row, error], config Config,
+) iter.Seq2[model.Transaction, error] {
+	return func(yield func(model.Transaction, error) bool) {
+		fail := func(err error) {
+			yield(model.Transaction{}, fmt.Errorf("filereader: streamTransactions: %w", err))
 		}
 
-		transactions = append(transactions, transaction)
-	}
+		quarantine := newQuarantine(config)
+		defer quarantine.close()
 
-	return transactions, nil
-}
+		for row, err := range rows {
+			if err == nil {
+				err = ctx.Err()
+			}
+
+			if err != nil {
+				fail(err)
+				return
+			}
+
+			if row.isValid() {
+				if !yield(row.Transaction, nil) {
+					return
+				}
 
-func isNotValidHeader(header []string) bool {
-	return !isValidHeader(header)
+				continue
+			}
+
+			switch config.InvalidRows {
+			case RejectFile:
+				fail(row.err())
+				return
+			case SkipInvalidRows:
+				continue
+			case QuarantineInvalidRows:
+				if errQuarantine := quarantine.add(row); errQuarantine != nil {
+					fail(errQuarantine)
+					return
+				}
+			}
+		}
+
+		if err := quarantine.close(); err != nil {
+			fail(err)
+		}
+	}
 }
 
-func isValidHeader(header []string) bool {
-	return strings.ToLower(header[0]) == "id" &&
-		strings.ToLower(header[1]) == "date" &&
-		strings.ToLower(header[2]) == "transaction"
+// scanRows yields every data row of the source along with its validation errors, if any.
+// Only errors that prevent reading the rest of the source, like an invalid header, are yielded as errors.
+func scanRows(source io.Reader, config Config) iter.Seq2[Row, error] {
+	return func(yield func(Row, error) bool) {
+		fail := func(err error) {
+			yield(Row{}, fmt.Errorf("filereader: scanRows: %w", err))
+		}
+
+		mapping := config.Columns.orDefault()
+
+		csvReader := csv.NewReader(source)
+		csvReader.Comma = mapping.Delimiter
+		csvReader.ReuseRecord = true
+
+		var header []string
+		if !mapping.NoHeader {
+			var err error
+			if header, err = csvReader.Read(); err != nil {
+				fail(fmt.Errorf("%w: %w", ErrInvalidFile, err))
+				return
+			}
+		}
+
+		layout, err := mapping.resolve(header)
+		if err != nil {
+			fail(err)
+			return
+		}
+
+		dates := newDateParser(config)
+
+		for sequence := 0; ; sequence++ {
+			fields, errRead := csvReader.Read()
+			if errors.Is(errRead, io.EOF) {
+				return
+			}
+
+			var row Row
+
+			var parseErr *csv.ParseError
+			switch {
+			case errors.As(errRead, &parseErr):
+				row = Row{
+					Line:   parseErr.StartLine,
+					Fields: fields,
+					Errors: []*RowError{{
+						Line:  parseErr.StartLine,
+						Value: strings.Join(fields, string(mapping.Delimiter)),
+						Err:   fmt.Errorf("%w: %w", ErrInvalidFile, parseErr.Err),
+					}},
+				}
+			case errRead != nil:
+				fail(fmt.Errorf("%w: %w", ErrInvalidFile, errRead))
+				return
+			default:
+				line, _ := csvReader.FieldPos(0)
+				row = buildRow(fields, line, sequence, layout, dates)
+			}
+
+			row.Header = layout.header
+
+			if !yield(row, nil) {
+				return
+			}
+		}
+	}
 }
 
-func buildTransaction(rowData []string) (model.Transaction, error) {
-	fail := func(err error) (model.Transaction, error) {
-		return model.Transaction{}, fmt.Errorf("filereader: buildTransaction: %w", err)
+func buildRow(fields []string, line, sequence int, layout columnLayout, dates *dateParser) Row {
+	row := Row{Line: line, Fields: fields}
+	invalid := func(index int, fallback, value string, err error) {
+		row.Errors = append(row.Errors, &RowError{
+			Line:   line,
+			Column: layout.columnName(index, fallback),
+			Value:  value,
+			Err:    err,
+		})
 	}
 
-	id, err := strconv.Atoi(rowData[0])
-	if err != nil {
-		return fail(err)
+	id, ref := sequence, ""
+	if layout.id >= 0 {
+		value, _ := layout.field(fields, layout.id)
+
+		var err error
+		if id, ref, err = parseID(value, sequence); err != nil {
+			invalid(layout.id, idColumn, value, err)
+		}
 	}
 
-	date, err := parseDate(rowData[1])
+	dateValue, _ := layout.field(fields, layout.date)
+	date, err := dates.parseDate(strings.TrimSpace(dateValue))
 	if err != nil {
-		return fail(err)
+		invalid(layout.date, dateColumn, dateValue, err)
 	}
 
-	amount, err := decimal.Parse(rowData[2])
+	amountValue, _ := layout.field(fields, layout.amount)
+	amount, err := decimal.Parse(strings.TrimSpace(amountValue))
 	if err != nil {
-		return fail(ErrInvalidAmount)
+		invalid(layout.amount, amountColumn, amountValue, ErrInvalidAmount)
+	}
+
+	var account string
+	if layout.account >= 0 {
+		value, _ := layout.field(fields, layout.account)
+		if account = strings.TrimSpace(value); account == "" {
+			invalid(layout.account, accountColumn, value, ErrInvalidAccount)
+		}
+	}
+
+	var attributes map[string]string
+	for name, index := range layout.kept {
+		if value, ok := layout.field(fields, index); ok {
+			if attributes == nil {
+				attributes = make(map[string]string, len(layout.kept))
+			}
+			attributes[name] = value
+		}
 	}
 
-	return model.Transaction{
-		ID:     id,
-		Date:   date,
-		Amount: amount,
-	}, nil
+	if ref != "" {
+		if attributes == nil {
+			attributes = make(map[string]string, 1)
+		}
+		attributes[strings.ToLower(layout.columnName(layout.id, idColumn))] = ref
+	}
+
+	row.Transaction = model.Transaction{
+		ID:         id,
+		Date:       date,
+		Amount:     amount,
+		Account:    account,
+		Attributes: attributes,
+	}
+
+	return row
 }
 
-func parseDate(datestr string) (time.Time, error) {
+// parseID is the id of a transaction when it is numeric. Other ids, e.g. the A-1 references of partners, are returned
+// as a reference to keep in the attributes, and the transaction is numbered in order instead, as for the FITID of OFX
+// statements. Blank ids are invalid.
+func parseID(value string, sequence int) (int, string, error) {
+	trimmed := strings.TrimSpace(value)
+	if id, err := strconv.Atoi(trimmed); err == nil {
+		return id, "", nil
+	}
+
+	if trimmed == "" {
+		return sequence, "", ErrInvalidID
+	}
+
+	return sequence, trimmed, nil
+}
+
+type dateParser struct {
+	config    Config
+	year      int
+	lastMonth time.Month
+}
+
+func newDateParser(config Config) *dateParser {
+	year := config.DefaultYear
+	if year == 0 {
+		year = time.Now().Year()
+	}
+
+	return &dateParser{config: config, year: year}
+}
+
+func (parser *dateParser) parseDate(datestr string) (time.Time, error) {
 	fail := func(err error) (time.Time, error) {
 		return time.Time{}, fmt.Errorf("filereader: parseDate %s: %w", datestr, err)
 	}
 
-	validDateLayouts := []string{"2006/01/02", "01/02", "1/02", "1/2"}
+	if date, err := time.Parse("2006/01/02", datestr); err == nil {
+		return parser.dated(date), nil
+	}
 
-	for _, layout := range validDateLayouts {
+	yearlessLayouts := []string{"01/02", "1/02", "1/2"}
+
+	for _, layout := range yearlessLayouts {
 		if date, err := time.Parse(layout, datestr); err == nil {
-			return date, nil
+			return parser.withYear(date)
 		}
 	}
 
 	return fail(ErrInvalidDateFormat)
 }
+
+// dated moves the year of the dates that follow to that of a date with a year, when it is inferred.
+func (parser *dateParser) dated(date time.Time) time.Time {
+	if parser.config.YearInference == YearRollover {
+		parser.year, parser.lastMonth = date.Year(), date.Month()
+	}
+
+	return date
+}
+
+func (parser *dateParser) withYear(yearless time.Time) (time.Time, error) {
+	if parser.config.YearInference == YearRollover && yearless.Month() < parser.lastMonth {
+		parser.year++
+	}
+	parser.lastMonth = yearless.Month()
+
+	date := time.Date(parser.year, yearless.Month(), yearless.Day(), 0, 0, 0, 0, time.UTC)
+	if date.Day() != yearless.Day() {
+		return time.Time{}, fmt.Errorf("filereader: withYear %s %d: %w",
+			yearless.Format("01/02"), parser.year, ErrInvalidDateFormat)
+	}
+
+	return date, nil
+}
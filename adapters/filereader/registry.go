@@ -0,0 +1,430 @@
+package filereader
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"stori/model"
+)
+
+const (
+	SchemeFile  = "file"
+	SchemeS3    = "s3"
+	SchemeHTTP  = "http"
+	SchemeHTTPS = "https"
+	SchemeStdin = "stdin"
+
+	// stdinURI reads the standard input, as most command line tools do.
+	stdinURI = "-"
+)
+
+type (
+	// Source is the content of a file opened by a transport.
+	Source struct {
+		io.ReadCloser
+		// Name is the path or the key of the file, whose extension tells its format. It may be empty.
+		Name string
+		// MediaType is the type the file was served with, if any, e.g. text/csv.
+		MediaType string
+	}
+
+	// Transport opens the files of a URI scheme, whatever their format.
+	Transport interface {
+		Open(ctx context.Context, uri string, config Config) (Source, error)
+		// Checksum fingerprints the file without decoding it. An empty checksum leaves the file out of the
+		// duplicate check.
+		Checksum(ctx context.Context, uri string, config Config) (string, error)
+	}
+
+	// Decoder yields the rows of a content of its format, invalid rows included.
+	Decoder func(source io.Reader, config Config) iter.Seq2[Row, error]
+
+	// FormatSpec tells how the files of a format are recognized and decoded.
+	FormatSpec struct {
+		Decoder Decoder
+		// Extensions of the names of its files, e.g. ".csv".
+		Extensions []string
+		// MediaTypes its files are served with, e.g. text/csv.
+		MediaTypes []string
+		// Sniff recognizes its content by its first bytes, when neither the name nor the media type tell.
+		Sniff func(head []byte) bool
+	}
+
+	// Input is a file to be processed on its own.
+	Input struct {
+		// URI is the URI of the file, followed by #member for a member of a zip archive, e.g.
+		// s3://exports/nightly.zip#transactions.csv.
+		URI    string
+		Reader FileReader
+	}
+
+	// Registry resolves a URI into a reader, with the transport of its scheme and the decoder of its format.
+	// Transports and formats can be registered at any time, e.g. from the init function of another package.
+	Registry struct {
+		mu         sync.RWMutex
+		transports map[string]Transport
+		formats    map[Format]FormatSpec
+		// sniffed are the formats in the order they were registered, which is the order they are sniffed in.
+		sniffed []Format
+		// fallback is the format of files nothing else recognizes.
+		fallback Format
+	}
+)
+
+// defaultRegistry is the registry of RegisterTransport, RegisterFormat and NewReader.
+var defaultRegistry = NewRegistry() //nolint:gochecknoglobals // Registered into by other packages
+
+// NewRegistry is a registry of the built-in transports (file, s3, http, https and stdin) and formats (CSV, OFX and
+// JSON).
+// Files of an unknown format are read as CSV.
+func NewRegistry() *Registry {
+	registry := &Registry{
+		transports: make(map[string]Transport),
+		formats:    make(map[Format]FormatSpec),
+		fallback:   FormatCSV,
+	}
+
+	registry.RegisterTransport(SchemeFile, fileTransport{})
+	registry.RegisterTransport(SchemeS3, s3Transport{})
+	registry.RegisterTransport(SchemeHTTP, httpTransport{})
+	registry.RegisterTransport(SchemeHTTPS, httpTransport{})
+	registry.RegisterTransport(SchemeStdin, &stdinTransport{})
+
+	registry.RegisterFormat(FormatCSV, FormatSpec{
+		Decoder:    scanRows,
+		Extensions: []string{".csv"},
+		MediaTypes: []string{"text/csv", "application/csv"},
+	})
+	registry.RegisterFormat(FormatOFX, FormatSpec{
+		Decoder:    func(source io.Reader, _ Config) iter.Seq2[Row, error] { return scanOFXRows(source) },
+		Extensions: []string{".ofx", ".qfx"},
+		MediaTypes: []string{"application/x-ofx", "application/ofx", "application/x-qfx", "application/vnd.intu.qfx"},
+		Sniff:      sniffOFX,
+	})
+	registry.RegisterFormat(FormatJSON, FormatSpec{
+		Decoder:    scanJSONRows,
+		Extensions: []string{".json", ".ndjson", ".jsonl"},
+		MediaTypes: []string{"application/json", "application/x-ndjson", "application/jsonl", "application/jsonlines"},
+		Sniff:      sniffJSON,
+	})
+
+	return registry
+}
+
+// RegisterTransport makes the transport open the URIs of the scheme, replacing the one registered before, if any.
+func (registry *Registry) RegisterTransport(scheme string, transport Transport) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.transports[strings.ToLower(scheme)] = transport
+}
+
+// RegisterFormat makes the spec decode the files of the format, replacing the one registered before, if any.
+func (registry *Registry) RegisterFormat(format Format, spec FormatSpec) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if _, ok := registry.formats[format]; !ok {
+		registry.sniffed = append(registry.sniffed, format)
+	}
+
+	registry.formats[format] = spec
+}
+
+// NewReader is the reader of the file at the URI: a local path, a file://, s3://, http:// or https:// URI, "-" for
+// the standard input, or a URI of a registered scheme. Its format is Config.Format when set, or else detected when
+// the file is read.
+func (registry *Registry) NewReader(uri string, config Config) (FileReader, error) {
+	reader, err := registry.newReader(uri, config)
+	if err != nil {
+		return nil, fmt.Errorf("filereader: Registry: NewReader: %w", err)
+	}
+
+	return reader, nil
+}
+
+// Inputs are the files to be processed on their own: the file at the URI, or each member of it when it is a zip
+// archive whose members are read separately, as Config.Compression.Members tells. Telling the archive apart from
+// other files opens it, so that it is only done for SeparateMembers.
+func (registry *Registry) Inputs(ctx context.Context, uri string, config Config) ([]Input, error) {
+	fail := func(err error) ([]Input, error) {
+		return nil, fmt.Errorf("filereader: Registry: Inputs: %w", err)
+	}
+
+	reader, err := registry.newReader(uri, config)
+	if err != nil {
+		return fail(err)
+	}
+
+	if config.Compression.Members != SeparateMembers {
+		return []Input{{URI: uri, Reader: reader}}, nil
+	}
+
+	members, err := reader.members(ctx)
+	if err != nil {
+		return fail(err)
+	}
+
+	if members == nil {
+		return []Input{{URI: uri, Reader: reader}}, nil
+	}
+
+	inputs := make([]Input, len(members))
+	for i, member := range members {
+		memberReader := reader
+		memberReader.transport = memberTransport{archive: reader.transport, member: member}
+		memberReader.config.QuarantinePath = memberQuarantinePath(config.QuarantinePath, member)
+		inputs[i] = Input{URI: uri + "#" + member, Reader: memberReader}
+	}
+
+	return inputs, nil
+}
+
+// memberQuarantinePath is the quarantine file of a member of an archive, named after it, e.g. quarantine.july.csv
+// for the member july.csv when the path is quarantine.csv, so that the members do not overwrite the invalid rows of
+// one another.
+func memberQuarantinePath(quarantinePath, member string) string {
+	if quarantinePath == "" {
+		return ""
+	}
+
+	extension := filepath.Ext(quarantinePath)
+	name := strings.NewReplacer("/", "_", "\\", "_").Replace(member)
+	if !strings.HasSuffix(strings.ToLower(name), strings.ToLower(extension)) {
+		name += extension
+	}
+
+	return strings.TrimSuffix(quarantinePath, extension) + "." + name
+}
+
+func (registry *Registry) newReader(uri string, config Config) (URIReader, error) {
+	scheme := schemeOf(uri)
+
+	registry.mu.RLock()
+	transport, ok := registry.transports[scheme]
+	registry.mu.RUnlock()
+
+	if !ok {
+		return URIReader{}, fmt.Errorf("%w: %s", ErrUnsupportedScheme, scheme)
+	}
+
+	if config.Format != "" {
+		if _, errSpec := registry.spec(config.Format); errSpec != nil {
+			return URIReader{}, errSpec
+		}
+	}
+
+	config.S3.version = &objectVersion{}
+	config.HTTP.version = &httpVersion{}
+
+	return URIReader{uri: uri, config: config, transport: transport, registry: registry}, nil
+}
+
+// RegisterTransport registers the transport of the scheme for NewReader.
+func RegisterTransport(scheme string, transport Transport) {
+	defaultRegistry.RegisterTransport(scheme, transport)
+}
+
+// RegisterFormat registers the spec of the format for NewReader.
+func RegisterFormat(format Format, spec FormatSpec) {
+	defaultRegistry.RegisterFormat(format, spec)
+}
+
+// NewReader is the reader of the file at the URI, with the transports and formats registered so far.
+func NewReader(uri string, config Config) (FileReader, error) {
+	return defaultRegistry.NewReader(uri, config)
+}
+
+// Inputs are the inputs of the file at the URI, with the transports and formats registered so far.
+func Inputs(ctx context.Context, uri string, config Config) ([]Input, error) {
+	return defaultRegistry.Inputs(ctx, uri, config)
+}
+
+// schemeOf is the lower case scheme of the URI, the file scheme for paths.
+func schemeOf(uri string) string {
+	if uri == stdinURI {
+		return SchemeStdin
+	}
+
+	scheme, _, found := strings.Cut(uri, "://")
+	if !found {
+		return SchemeFile
+	}
+
+	return strings.ToLower(scheme)
+}
+
+func (registry *Registry) spec(format Format) (FormatSpec, error) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	spec, ok := registry.formats[format]
+	if !ok {
+		return FormatSpec{}, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+
+	return spec, nil
+}
+
+// detect tells the format of the source from the extension of its name, its media type, or else its first bytes,
+// which are only read when needed.
+func (registry *Registry) detect(source Source, head func() []byte) Format {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	extension := strings.ToLower(path.Ext(source.Name))
+	for _, format := range registry.sniffed {
+		if extension != "" && slices.Contains(registry.formats[format].Extensions, extension) {
+			return format
+		}
+	}
+
+	for _, format := range registry.sniffed {
+		if source.MediaType != "" && slices.Contains(registry.formats[format].MediaTypes, source.MediaType) {
+			return format
+		}
+	}
+
+	for _, format := range registry.sniffed {
+		if sniff := registry.formats[format].Sniff; sniff != nil && sniff(head()) {
+			return format
+		}
+	}
+
+	return registry.fallback
+}
+
+// URIReader reads the file at a URI with the transport of its scheme, decoding it with the decoder of its format.
+type URIReader struct {
+	uri       string
+	config    Config
+	transport Transport
+	registry  *Registry
+}
+
+func (reader URIReader) ReadTransactions(ctx context.Context) ([]model.Transaction, error) {
+	transactions, err := collectTransactions(reader.StreamTransactions(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("filereader: URIReader: ReadTransactions: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// StreamTransactions yields the transactions one row at a time, as the transport reads the file.
+// Each iteration over the returned sequence opens the file again.
+func (reader URIReader) StreamTransactions(ctx context.Context) iter.Seq2[model.Transaction, error] {
+	return func(yield func(model.Transaction, error) bool) {
+		fail := func(err error) {
+			yield(model.Transaction{}, fmt.Errorf("filereader: URIReader: StreamTransactions: %w", err))
+		}
+
+		rows, source, err := reader.open(ctx)
+		if err != nil {
+			fail(err)
+			return
+		}
+
+		defer source.Close()
+
+		for transaction, errRow := range streamTransactions(ctx, rows, reader.config) {
+			if errRow != nil {
+				fail(errRow)
+				return
+			}
+
+			if !yield(transaction, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (reader URIReader) Checksum(ctx context.Context) (string, error) {
+	checksum, err := reader.transport.Checksum(ctx, reader.uri, reader.config)
+	if err != nil {
+		return "", fmt.Errorf("filereader: URIReader: Checksum: %w", err)
+	}
+
+	return checksum, nil
+}
+
+// Validate reads the whole file and reports every invalid row instead of stopping at the first one.
+func (reader URIReader) Validate(ctx context.Context) (ValidationReport, error) {
+	fail := func(err error) (ValidationReport, error) {
+		return ValidationReport{}, fmt.Errorf("filereader: URIReader: Validate: %w", err)
+	}
+
+	rows, source, err := reader.open(ctx)
+	if err != nil {
+		return fail(err)
+	}
+
+	defer source.Close()
+
+	report, err := validate(ctx, rows)
+	if err != nil {
+		return fail(err)
+	}
+
+	return report, nil
+}
+
+// members are the names of the members of the file when it is a zip archive, nil otherwise.
+func (reader URIReader) members(ctx context.Context) ([]string, error) {
+	source, err := reader.transport.Open(ctx, reader.uri, reader.config)
+	if err != nil {
+		return nil, err
+	}
+
+	defer source.Close()
+
+	content := bufio.NewReaderSize(source, sniffLength)
+	head, _ := content.Peek(magicLength)
+
+	if detectCompression(source, head) != CompressionZip {
+		return nil, nil
+	}
+
+	archive, _, err := openZip(source, content)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := zipMembers(archive, reader.config.Compression)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]string, len(files))
+	for i, file := range files {
+		members[i] = file.Name
+	}
+
+	return members, nil
+}
+
+// open opens the file with the transport and decodes it with the decoder of its format, decompressing it first when
+// it is compressed.
+func (reader URIReader) open(ctx context.Context) (iter.Seq2[Row, error], io.Closer, error) {
+	source, err := reader.transport.Open(ctx, reader.uri, reader.config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := reader.registry.decompress(source, reader.config)
+	if err != nil {
+		source.Close()
+		return nil, nil, err
+	}
+
+	return rows, source, nil
+}
@@ -0,0 +1,204 @@
+package filereader_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"iter"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stori/adapters/filereader"
+	"stori/model"
+	"stori/test"
+)
+
+// memoryTransport serves files kept in memory, by the path of their mem:// URI.
+type memoryTransport map[string]string
+
+func (transport memoryTransport) Open(_ context.Context, uri string, _ filereader.Config) (filereader.Source, error) {
+	name := strings.TrimPrefix(uri, "mem://")
+	content, ok := transport[name]
+	if !ok {
+		return filereader.Source{}, filereader.ErrFileNotFound
+	}
+
+	return filereader.Source{ReadCloser: io.NopCloser(strings.NewReader(content)), Name: name}, nil
+}
+
+func (transport memoryTransport) Checksum(_ context.Context, uri string, _ filereader.Config) (string, error) {
+	return "mem:" + strings.TrimPrefix(uri, "mem://"), nil
+}
+
+// decodePipes decodes lines like 7|2024-07-15|60.5, which are invalid when their amount is not a number.
+func decodePipes(source io.Reader, _ filereader.Config) iter.Seq2[filereader.Row, error] {
+	return func(yield func(filereader.Row, error) bool) {
+		scanner := bufio.NewScanner(source)
+		for line := 1; scanner.Scan(); line++ {
+			fields := strings.Split(scanner.Text(), "|")
+			row := filereader.Row{Line: line, Header: []string{"id", "date", "amount"}, Fields: fields}
+
+			id, _ := strconv.Atoi(fields[0])
+			date, _ := time.Parse(time.DateOnly, fields[1])
+			amount, err := decimal.Parse(fields[2])
+			if err != nil {
+				row.Errors = []*filereader.RowError{
+					{Line: line, Column: "amount", Value: fields[2], Err: filereader.ErrInvalidAmount},
+				}
+			}
+
+			row.Transaction = model.Transaction{ID: id, Date: date, Amount: amount}
+			if !yield(row, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestNewReader_SameAsLocalFile(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	server := httptest.NewServer(serveFile(t, "statement_xml.qfx", ""))
+	t.Cleanup(server.Close)
+
+	testCases := []struct {
+		name     string
+		uri      string
+		filename string
+	}{
+		{name: "When file URI", uri: "file://testdata/several_transactions.csv", filename: "several_transactions.csv"},
+		{name: "When sniffed", uri: "testdata/statement_without_extension", filename: "statement_sgml.ofx"},
+		{name: "When URL", uri: server.URL + "/statement.qfx", filename: "statement_xml.qfx"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			expected := localTransactions(t, tc.filename)
+
+			sut, err := filereader.NewReader(tc.uri, filereader.Config{})
+			require.NoError(t, err)
+
+			// Act
+			transactions, err := sut.ReadTransactions(context.Background())
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, expected, transactions)
+		})
+	}
+}
+
+func TestNewReader_WhenFormatIsForced_DecodedWithIt(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	content, err := os.ReadFile("testdata/statement_xml.qfx")
+	require.NoError(t, err)
+
+	path := t.TempDir() + "/statement.txt"
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+
+	sut, err := filereader.NewReader(path, filereader.Config{Format: filereader.FormatOFX})
+	require.NoError(t, err)
+
+	// Act
+	transactions, err := sut.ReadTransactions(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotEmpty(t, transactions)
+}
+
+func TestNewReader_WhenUnsupported_Error(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		uri           string
+		config        filereader.Config
+		expectedError error
+	}{
+		{name: "When scheme is unknown", uri: "ftp://partner/july.csv", expectedError: filereader.ErrUnsupportedScheme},
+		{
+			name: "When format is unknown", uri: "july.xlsx", config: filereader.Config{Format: "xlsx"},
+			expectedError: filereader.ErrUnsupportedFormat,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			_, err := filereader.NewReader(tc.uri, tc.config)
+
+			// Assert
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestRegistry_WhenTransportAndFormatRegistered_ReadsThem(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	registry := filereader.NewRegistry()
+	registry.RegisterTransport("mem", memoryTransport{
+		"july.pipes": "1|2024-07-15|60.5\n2|2024-07-28|abc\n3|2024-08-02|-10.3\n",
+	})
+	registry.RegisterFormat("pipes", filereader.FormatSpec{Decoder: decodePipes, Extensions: []string{".pipes"}})
+
+	sut, err := registry.NewReader("mem://july.pipes", filereader.Config{InvalidRows: filereader.SkipInvalidRows})
+	require.NoError(t, err)
+
+	// Act
+	transactions, err := sut.ReadTransactions(context.Background())
+	report, errValidate := sut.Validate(context.Background())
+	checksum, errChecksum := sut.Checksum(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, 1, transactions[0].ID)
+	assert.Equal(t, decimal.MustParse("-10.3"), transactions[1].Amount)
+
+	require.NoError(t, errValidate)
+	assert.Equal(t, 3, report.Rows)
+	require.Len(t, report.Errors, 1)
+	assert.Equal(t, 2, report.Errors[0].Line)
+
+	require.NoError(t, errChecksum)
+	assert.Equal(t, "mem:july.pipes", checksum)
+}
+
+func TestRegistry_WhenFileIsEmpty_Error(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	registry := filereader.NewRegistry()
+	registry.RegisterTransport("mem", memoryTransport{"july.csv": ""})
+
+	sut, err := registry.NewReader("mem://july.csv", filereader.Config{})
+	require.NoError(t, err)
+
+	// Act
+	_, err = sut.ReadTransactions(context.Background())
+
+	// Assert
+	require.Error(t, err)
+	assert.ErrorIs(t, err, filereader.ErrFileIsEmpty)
+}
@@ -1,103 +1,135 @@
 package filereader
 
 import (
+	"context"
 	"fmt"
-	"net/url"
-	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/aws/aws-sdk-go/service/s3/s3manager"
-
-	"stori/model"
 )
 
-const awsRegion = "us-east-1"
+// DefaultS3Region is the region used when neither the config nor the environment tell one.
+const DefaultS3Region = "us-east-1"
 
-type S3 struct {
-	fileURI string
-}
+// s3Transport streams objects given by s3://bucket/key URIs, one part at a time, without writing them to disk.
+type s3Transport struct{}
 
-func NewS3Reader(fileURI string) S3 {
-	return S3{fileURI: fileURI}
-}
+func (s3Transport) Open(ctx context.Context, uri string, config Config) (Source, error) {
+	bucket, key, err := parseS3URI(uri)
+	if err != nil {
+		return Source{}, fmt.Errorf("%w: %w", ErrInvalidURI, err)
+	}
 
-func (reader S3) ReadTransactions() ([]model.Transaction, error) {
-	fail := func(err error) ([]model.Transaction, error) {
-		return nil, fmt.Errorf("filereader: S3: ReadTransactions: %w", err)
+	client, err := NewS3Client(config.S3)
+	if err != nil {
+		return Source{}, err
 	}
 
-	bucket, key, err := parseS3URI(reader.fileURI)
+	object, err := openS3Object(ctx, client, config.S3, bucket, key)
 	if err != nil {
-		return fail(fmt.Errorf("%w: %w", ErrInvalidURI, err))
+		return Source{}, err
 	}
 
-	destPath := filepath.Join(os.TempDir(), key)
-	if errDownload := downloadFileFromS3(bucket, key, destPath); errDownload != nil {
-		return fail(errDownload)
+	return Source{ReadCloser: object, Name: key, MediaType: object.mediaType}, nil
+}
+
+// Checksum fingerprints the object with its ETag, without downloading it.
+func (s3Transport) Checksum(ctx context.Context, uri string, config Config) (string, error) {
+	bucket, key, err := parseS3URI(uri)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidURI, err)
 	}
 
-	transactions, err := NewLocalReader(destPath).ReadTransactions()
+	etag, err := headS3ETag(ctx, config.S3, bucket, key)
 	if err != nil {
-		return fail(err)
+		return "", err
 	}
 
-	return transactions, nil
+	return etagPrefix + etag, nil
 }
 
+// parseS3URI splits s3://bucket/key without unescaping it, as keys are taken as they are: a key may hold #, ? or %,
+// e.g. s3://statements/q1#2.csv is the key q1#2.csv.
 func parseS3URI(s3URI string) (bucket, key string, err error) {
 	fail := func(err error) (string, string, error) {
 		return "", "", fmt.Errorf("filereader: parseS3URI: %w", err)
 	}
 
-	parsedURL, err := url.Parse(s3URI)
-	if err != nil {
-		return fail(fmt.Errorf("failed to parse S3 URI: %w", err))
+	scheme, location, found := strings.Cut(s3URI, "://")
+	if !found || !strings.EqualFold(scheme, SchemeS3) {
+		return fail(fmt.Errorf("invalid S3 URI scheme: %s", scheme))
 	}
 
-	if parsedURL.Scheme != "s3" {
-		return fail(fmt.Errorf("invalid S3 URI scheme: %s", parsedURL.Scheme))
+	bucket, key, _ = strings.Cut(location, "/")
+	if bucket == "" {
+		return fail(fmt.Errorf("missing S3 bucket: %s", s3URI))
 	}
 
-	bucket = parsedURL.Host
-	key = strings.TrimPrefix(parsedURL.Path, "/")
-
 	return bucket, key, nil
 }
 
-func downloadFileFromS3(bucket, key, destPath string) error {
-	fail := func(err error) error {
-		return fmt.Errorf("filereader: downloadFileFromS3: %w", err)
+// NewS3Client is an S3 client as configured, for whoever needs to reach the same buckets as s3:// URIs.
+func NewS3Client(config S3Config) (*s3.S3, error) {
+	sess, err := newS3Session(config)
+	if err != nil {
+		return nil, fmt.Errorf("filereader: NewS3Client: %w", err)
 	}
 
-	sess, err := session.NewSession(&aws.Config{
-		Region:      aws.String(awsRegion),
-		Credentials: credentials.AnonymousCredentials,
+	return s3.New(sess), nil
+}
+
+func newS3Session(config S3Config) (*session.Session, error) {
+	awsConfig := aws.Config{S3ForcePathStyle: aws.Bool(config.PathStyle)}
+	if config.Region != "" {
+		awsConfig.Region = aws.String(config.Region)
+	}
+
+	if config.Endpoint != "" {
+		awsConfig.Endpoint = aws.String(config.Endpoint)
+	}
+
+	if config.Anonymous {
+		awsConfig.Credentials = credentials.AnonymousCredentials
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            awsConfig,
+		Profile:           config.Profile,
+		SharedConfigState: session.SharedConfigEnable,
 	})
 	if err != nil {
-		return fail(fmt.Errorf("%w: %w", ErrS3Connection, err))
+		return nil, fmt.Errorf("%w: %w", ErrS3Connection, err)
+	}
+
+	if aws.StringValue(sess.Config.Region) == "" {
+		sess.Config.Region = aws.String(DefaultS3Region)
 	}
 
-	file, err := os.Create(destPath)
+	return sess, nil
+}
+
+// headS3ETag returns the ETag of the object, without the quotes S3 wraps it in, and pins it as openS3Object does.
+func headS3ETag(ctx context.Context, config S3Config, bucket, key string) (string, error) {
+	fail := func(err error) (string, error) {
+		return "", fmt.Errorf("filereader: headS3ETag: %w", err)
+	}
+
+	client, err := NewS3Client(config)
 	if err != nil {
-		return fail(fmt.Errorf("%w (%s): %w", ErrFileCreation, destPath, err))
+		return fail(err)
 	}
-	defer file.Close()
 
-	downloader := s3manager.NewDownloader(sess)
-	_, err = downloader.Download(
-		file,
-		&s3.GetObjectInput{
-			Bucket: aws.String(bucket),
-			Key:    aws.String(key),
-		})
+	head, err := client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket:  aws.String(bucket),
+		Key:     aws.String(key),
+		IfMatch: config.version.ifMatch(),
+	})
 	if err != nil {
-		return fail(fmt.Errorf("%w: %w", ErrDownloadFile, err))
+		return fail(fmt.Errorf("%w: %w", ErrS3Connection, err))
 	}
 
-	return nil
+	return strings.Trim(config.version.pin(aws.StringValue(head.ETag)), `"`), nil
 }
@@ -1,10 +1,12 @@
 package filereader_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/govalues/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -14,18 +16,25 @@ import (
 )
 
 const (
-	s3Prefix = "s3://jcamilostori"
+	s3Prefix = "s3://" + minioBucket
 )
 
+// minioReader reads the file of the testdata folder uploaded to MinIO.
+func minioReader(t *testing.T, filename string) filereader.FileReader {
+	t.Helper()
+
+	return newReader(t, fmt.Sprintf("%s/%s", s3Prefix, filename), filereader.Config{S3: minioS3(t)})
+}
+
 func TestReadTransactionsFromS3_WhenFileDoesNotExist_Error(t *testing.T) {
 	test.IntegrationTest(t)
 	t.Parallel()
 
 	// Arrange
-	sut := filereader.NewS3Reader(fmt.Sprintf("%s/%s", s3Prefix, "non-existent-file.csv"))
+	sut := minioReader(t, "non-existent-file.csv")
 
 	// Act
-	_, err := sut.ReadTransactions()
+	_, err := sut.ReadTransactions(context.Background())
 
 	// Assert
 	require.Error(t, err)
@@ -37,10 +46,10 @@ func TestReadTransactionsFromS3_WhenSingleTransaction_Success(t *testing.T) {
 	t.Parallel()
 
 	// Arrange
-	sut := filereader.NewS3Reader(fmt.Sprintf("%s/%s", s3Prefix, "single_transaction.csv"))
+	sut := minioReader(t, "single_transaction.csv")
 
 	// Act
-	transactions, err := sut.ReadTransactions()
+	transactions, err := sut.ReadTransactions(context.Background())
 
 	// Assert
 	require.NoError(t, err)
@@ -55,10 +64,10 @@ func TestReadTransactionsFromS3_WhenSeveralTransactions_Success(t *testing.T) {
 	t.Parallel()
 
 	// Arrange
-	sut := filereader.NewS3Reader(fmt.Sprintf("%s/%s", s3Prefix, "several_transactions.csv"))
+	sut := minioReader(t, "several_transactions.csv")
 
 	// Act
-	transactions, err := sut.ReadTransactions()
+	transactions, err := sut.ReadTransactions(context.Background())
 
 	// Assert
 	require.NoError(t, err)
@@ -92,27 +101,27 @@ func TestReadTransactionsFromS3_WhenFileIsInvalid_Error(t *testing.T) {
 	}{
 		{
 			name:          "When file is empty",
-			filename:      "testdata/empty_file.csv",
+			filename:      "empty_file.csv",
 			expectedError: filereader.ErrFileIsEmpty,
 		},
 		{
 			name:          "When file has invalid header",
-			filename:      "testdata/invalid_header.csv",
+			filename:      "invalid_header.csv",
 			expectedError: filereader.ErrInvalidHeader,
 		},
 		{
 			name:          "When file has invalid amount",
-			filename:      "testdata/invalid_amount.csv",
+			filename:      "invalid_amount.csv",
 			expectedError: filereader.ErrInvalidAmount,
 		},
 		{
 			name:          "When file has invalid date format",
-			filename:      "testdata/invalid_date.csv",
+			filename:      "invalid_date.csv",
 			expectedError: filereader.ErrInvalidDateFormat,
 		},
 		{
 			name:          "When file has invalid columns",
-			filename:      "testdata/invalid_columns.csv",
+			filename:      "invalid_columns.csv",
 			expectedError: filereader.ErrInvalidFile,
 		},
 	}
@@ -122,10 +131,10 @@ func TestReadTransactionsFromS3_WhenFileIsInvalid_Error(t *testing.T) {
 			t.Parallel()
 
 			// Arrange
-			sut := filereader.NewLocalReader(tc.filename)
+			sut := minioReader(t, tc.filename)
 
 			// Act
-			_, err := sut.ReadTransactions()
+			_, err := sut.ReadTransactions(context.Background())
 
 			// Assert
 			require.Error(t, err)
@@ -133,3 +142,75 @@ func TestReadTransactionsFromS3_WhenFileIsInvalid_Error(t *testing.T) {
 		})
 	}
 }
+
+func TestChecksumFromS3_WhenFileExists_ETagOfObject(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := minioReader(t, "single_transaction.csv")
+
+	// Act
+	checksum, err := sut.Checksum(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Regexp(t, `^etag:[^"]+$`, checksum)
+}
+
+func TestReadTransactionsFromS3_WhenWrongCredentials_Error(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	config := minioS3(t)
+	config.Profile = ""
+	config.Anonymous = true
+	sut := newReader(t, s3Prefix+"/leap_day.csv", filereader.Config{S3: config})
+
+	// Act
+	_, err := sut.ReadTransactions(context.Background())
+
+	// Assert
+	require.Error(t, err)
+	assert.ErrorIs(t, err, filereader.ErrDownloadFile)
+}
+
+func TestNewS3Client(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		config           filereader.S3Config
+		expectedRegion   string
+		expectedEndpoint string
+	}{
+		{
+			name:             "When region is set",
+			config:           filereader.S3Config{Region: "sa-east-1", Anonymous: true},
+			expectedRegion:   "sa-east-1",
+			expectedEndpoint: "https://s3.sa-east-1.amazonaws.com",
+		},
+		{
+			name:             "When endpoint is overridden",
+			config:           filereader.S3Config{Region: "us-east-1", Endpoint: "http://localhost:9000", PathStyle: true},
+			expectedRegion:   "us-east-1",
+			expectedEndpoint: "http://localhost:9000",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			client, err := filereader.NewS3Client(tc.config)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedRegion, aws.StringValue(client.Config.Region))
+			assert.Equal(t, tc.expectedEndpoint, client.Endpoint)
+			assert.Equal(t, tc.config.PathStyle, aws.BoolValue(client.Config.S3ForcePathStyle))
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package filereader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+const (
+	// DefaultMaxObjectSize is 1 GiB, far above the statements of a month but below what a Lambda can spend time on.
+	DefaultMaxObjectSize = 1 << 30
+	// DefaultPartSize is 64 MiB, so that most objects are read with a single request.
+	DefaultPartSize = 64 << 20
+)
+
+// s3Object streams the content of an object with one ranged GET per part, so that large objects are neither read
+// through a single long-lived connection nor written to disk. Every part is requested for the ETag the object had
+// when the reader first saw it, so that an object overwritten while it is read, or between the reads of an
+// execution, fails instead of mixing both versions.
+type s3Object struct {
+	ctx    context.Context
+	client s3iface.S3API
+	bucket string
+	key    string
+	etag   string
+	// mediaType is the Content-Type of the object, without its parameters.
+	mediaType string
+	size      int64
+	partSize  int64
+	offset    int64
+	partRead  int64
+	body      io.ReadCloser
+}
+
+// objectVersion is the ETag of the object the first time it was seen, either by a checksum or by a read.
+type objectVersion struct {
+	mu   sync.Mutex
+	etag string
+}
+
+// pinned is the ETag pinned so far, empty when there is none yet or no version at all.
+func (version *objectVersion) pinned() string {
+	if version == nil {
+		return ""
+	}
+
+	version.mu.Lock()
+	defer version.mu.Unlock()
+
+	return version.etag
+}
+
+// pin keeps the ETag unless another one was pinned before, and returns the pinned one.
+func (version *objectVersion) pin(etag string) string {
+	if version == nil {
+		return etag
+	}
+
+	version.mu.Lock()
+	defer version.mu.Unlock()
+
+	if version.etag == "" {
+		version.etag = etag
+	}
+
+	return version.etag
+}
+
+// ifMatch is the If-Match condition of the requests of the pinned version, nil when none is pinned yet.
+func (version *objectVersion) ifMatch() *string {
+	if etag := version.pinned(); etag != "" {
+		return aws.String(etag)
+	}
+
+	return nil
+}
+
+// openS3Object checks the object exists and is not too large, without reading it yet. It fails when the object is
+// not the version the config pinned anymore, and pins it otherwise.
+func openS3Object(ctx context.Context, client s3iface.S3API, config S3Config, bucket, key string) (*s3Object, error) {
+	fail := func(err error) (*s3Object, error) {
+		return nil, fmt.Errorf("filereader: openS3Object s3://%s/%s: %w", bucket, key, err)
+	}
+
+	head, err := client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket:  aws.String(bucket),
+		Key:     aws.String(key),
+		IfMatch: config.version.ifMatch(),
+	})
+	if err != nil {
+		return fail(fmt.Errorf("%w: %w", ErrDownloadFile, err))
+	}
+
+	size := aws.Int64Value(head.ContentLength)
+	if size == 0 {
+		return fail(ErrFileIsEmpty)
+	}
+
+	if maxSize := config.maxObjectSize(); maxSize >= 0 && size > maxSize {
+		return fail(fmt.Errorf("%w: %d bytes, at most %d", ErrObjectTooLarge, size, maxSize))
+	}
+
+	object := &s3Object{
+		ctx:       ctx,
+		client:    client,
+		bucket:    bucket,
+		key:       key,
+		etag:      config.version.pin(aws.StringValue(head.ETag)),
+		mediaType: mediaTypeOf(aws.StringValue(head.ContentType)),
+		size:      size,
+		partSize:  config.partSize(),
+	}
+
+	return object, nil
+}
+
+func (object *s3Object) Close() error {
+	if object.body == nil {
+		return nil
+	}
+
+	err := object.body.Close()
+	object.body = nil
+
+	return err
+}
+
+func (object *s3Object) Read(p []byte) (int, error) {
+	for object.offset < object.size {
+		if object.body == nil {
+			if err := object.openPart(); err != nil {
+				return 0, err
+			}
+		}
+
+		n, err := object.body.Read(p)
+		object.offset += int64(n)
+		object.partRead += int64(n)
+
+		switch {
+		case errors.Is(err, io.EOF) && object.partRead == 0:
+			_ = object.Close()
+			return 0, fmt.Errorf("%w: %w at byte %d", ErrDownloadFile, io.ErrUnexpectedEOF, object.offset)
+		case errors.Is(err, io.EOF):
+			// The next part starts where this one actually ended, should it be shorter than requested.
+			_ = object.Close()
+			if n == 0 {
+				continue
+			}
+		case err != nil:
+			return n, fmt.Errorf("%w: %w", ErrDownloadFile, err)
+		}
+
+		return n, nil
+	}
+
+	return 0, io.EOF
+}
+
+// ReadAt reads the bytes at the offset with a ranged GET of their own, so that zip archives are read in place.
+func (object *s3Object) ReadAt(p []byte, offset int64) (int, error) {
+	if offset >= object.size {
+		return 0, io.EOF
+	}
+
+	end := min(offset+int64(len(p)), object.size)
+
+	body, err := object.getRange(offset, end-1)
+	if err != nil {
+		return 0, err
+	}
+
+	defer body.Close()
+
+	n, err := io.ReadFull(body, p[:end-offset])
+	if err != nil {
+		return n, fmt.Errorf("%w: %w", ErrDownloadFile, err)
+	}
+
+	if n < len(p) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+// Size is the size of the object when it was opened.
+func (object *s3Object) Size() int64 {
+	return object.size
+}
+
+func (object *s3Object) openPart() error {
+	body, err := object.getRange(object.offset, min(object.offset+object.partSize, object.size)-1)
+	if err != nil {
+		return err
+	}
+
+	object.body, object.partRead = body, 0
+
+	return nil
+}
+
+// getRange requests the bytes from start to end, both included, of the version of the object that was opened.
+func (object *s3Object) getRange(start, end int64) (io.ReadCloser, error) {
+	output, err := object.client.GetObjectWithContext(object.ctx, &s3.GetObjectInput{
+		Bucket:  aws.String(object.bucket),
+		Key:     aws.String(object.key),
+		Range:   aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+		IfMatch: aws.String(object.etag),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDownloadFile, err)
+	}
+
+	return output.Body, nil
+}
+
+// readerFunc is an io.Reader of a function.
+type readerFunc func(p []byte) (int, error)
+
+func (read readerFunc) Read(p []byte) (int, error) {
+	return read(p)
+}
+
+func (config S3Config) maxObjectSize() int64 {
+	if config.MaxObjectSize == 0 {
+		return DefaultMaxObjectSize
+	}
+
+	return config.MaxObjectSize
+}
+
+func (config S3Config) partSize() int64 {
+	if config.PartSize <= 0 {
+		return DefaultPartSize
+	}
+
+	return config.PartSize
+}
@@ -0,0 +1,226 @@
+package filereader_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stori/adapters/filereader"
+	"stori/test"
+)
+
+// fakeS3Reader reads the object of a fake S3 holding the file of the testdata folder under the key.
+func fakeS3Reader(
+	t *testing.T, fake *test.FakeS3, filename, key string, config filereader.S3Config,
+) filereader.FileReader {
+	t.Helper()
+
+	content, err := os.ReadFile("testdata/" + filename)
+	require.NoError(t, err)
+	fake.Put(minioBucket, key, test.FakeObject{Content: content})
+
+	config.Endpoint, config.PathStyle, config.Anonymous = fake.URL(), true, true
+
+	return newReader(t, fmt.Sprintf("%s/%s", s3Prefix, key), filereader.Config{S3: config})
+}
+
+func TestStreamTransactionsFromS3_SameAsLocalFile(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		filename string
+		key      string
+	}{
+		{name: "When key has folders", filename: "several_transactions.csv", key: "daily/2024/07/july.csv"},
+		{name: "When OFX statement", filename: "statement_xml.qfx", key: "statements/july.qfx"},
+		{name: "When format is sniffed", filename: "statement_without_extension", key: "statements/july"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			expected := localTransactions(t, tc.filename)
+
+			sut := fakeS3Reader(t, test.NewFakeS3(t), tc.filename, tc.key, filereader.S3Config{})
+
+			// Act
+			transactions, err := sut.ReadTransactions(context.Background())
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, expected, transactions)
+		})
+	}
+}
+
+func TestStreamTransactionsFromS3_WhenLargerThanPart_ReadByRanges(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	expected := localTransactions(t, "several_transactions.csv")
+
+	fake := test.NewFakeS3(t)
+	sut := fakeS3Reader(t, fake, "several_transactions.csv", "july.csv", filereader.S3Config{PartSize: 32})
+
+	// Act
+	transactions, err := sut.ReadTransactions(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, expected, transactions)
+	assert.Equal(t, []string{"bytes=0-31", "bytes=32-63", "bytes=64-69"}, fake.Ranges())
+}
+
+func TestStreamTransactionsFromS3_WhenObjectChangesWhileRead_Error(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	fake := test.NewFakeS3(t)
+	sut := fakeS3Reader(t, fake, "several_transactions.csv", "july.csv", filereader.S3Config{PartSize: 16})
+
+	// Act
+	var err error
+	for _, err = range sut.StreamTransactions(context.Background()) {
+		if err != nil {
+			break
+		}
+
+		fake.Put(minioBucket, "july.csv", test.FakeObject{Content: []byte("id,date,transaction\n9,1/1,+1\n")})
+	}
+
+	// Assert
+	require.Error(t, err)
+	assert.ErrorIs(t, err, filereader.ErrDownloadFile)
+}
+
+func TestReadTransactionsFromS3_WhenObjectChangesBetweenReads_Error(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		firstSeen func(sut filereader.FileReader) error
+	}{
+		{
+			name: "When checksummed first",
+			firstSeen: func(sut filereader.FileReader) error {
+				_, err := sut.Checksum(context.Background())
+				return err
+			},
+		},
+		{
+			name: "When read first",
+			firstSeen: func(sut filereader.FileReader) error {
+				_, err := sut.ReadTransactions(context.Background())
+				return err
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			fake := test.NewFakeS3(t)
+			sut := fakeS3Reader(t, fake, "several_transactions.csv", "july.csv", filereader.S3Config{})
+			require.NoError(t, tc.firstSeen(sut))
+
+			fake.Put(minioBucket, "july.csv", test.FakeObject{Content: []byte("id,date,transaction\n9,1/1,+1\n")})
+
+			// Act
+			_, err := sut.ReadTransactions(context.Background())
+			_, errChecksum := sut.Checksum(context.Background())
+
+			// Assert
+			require.Error(t, err)
+			assert.ErrorIs(t, err, filereader.ErrDownloadFile)
+			require.Error(t, errChecksum, "the checksum is of the version read")
+		})
+	}
+}
+
+func TestReadTransactionsFromS3_WhenObjectIsRejected_Error(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		filename      string
+		config        filereader.S3Config
+		expectedError error
+	}{
+		{
+			name:          "When object is larger than the maximum",
+			filename:      "several_transactions.csv",
+			config:        filereader.S3Config{MaxObjectSize: 69},
+			expectedError: filereader.ErrObjectTooLarge,
+		},
+		{
+			name:          "When object is empty",
+			filename:      "empty_file.csv",
+			expectedError: filereader.ErrFileIsEmpty,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			sut := fakeS3Reader(t, test.NewFakeS3(t), tc.filename, tc.filename, tc.config)
+
+			// Act
+			_, err := sut.ReadTransactions(context.Background())
+
+			// Assert
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestReadTransactionsFromS3_WhenNoMaximumSize_Success(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := fakeS3Reader(t, test.NewFakeS3(t), "several_transactions.csv", "july.csv",
+		filereader.S3Config{MaxObjectSize: -1})
+
+	// Act
+	transactions, err := sut.ReadTransactions(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, transactions, 4)
+}
+
+func TestValidateFromS3_SameAsLocalFile(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	expected, err := newReader(t, "testdata/several_invalid_rows.csv", filereader.Config{}).
+		Validate(context.Background())
+	require.NoError(t, err)
+
+	sut := fakeS3Reader(t, test.NewFakeS3(t), "several_invalid_rows.csv", "july.csv", filereader.S3Config{PartSize: 8})
+
+	// Act
+	report, err := sut.Validate(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, expected, report)
+}
@@ -0,0 +1,163 @@
+package filereader
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type (
+	// fileTransport opens local files, given by their path or a file:// URI. Zip archives are read in place and other
+	// files are streamed, so that they are never fully loaded in memory, even when they are decompressed.
+	fileTransport struct{}
+
+	// localFile is a local file that tells its size, so that zip archives are read in place.
+	localFile struct {
+		*os.File
+		size int64
+	}
+
+	// memoryFile is content kept in memory, which zip archives are read from in place as well.
+	memoryFile struct {
+		*bytes.Reader
+	}
+)
+
+func (fileTransport) Open(_ context.Context, uri string, _ Config) (Source, error) {
+	filePath := strings.TrimPrefix(uri, SchemeFile+"://")
+
+	file, err := openCVSFile(filePath)
+	if err != nil {
+		return Source{}, err
+	}
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return Source{}, err
+	}
+
+	return Source{ReadCloser: localFile{File: file, size: fileInfo.Size()}, Name: filePath}, nil
+}
+
+func (fileTransport) Checksum(ctx context.Context, uri string, _ Config) (string, error) {
+	return fileChecksum(ctx, strings.TrimPrefix(uri, SchemeFile+"://"))
+}
+
+func openCVSFile(filePath string) (*os.File, error) {
+	fail := func(err error) (*os.File, error) {
+		return nil, fmt.Errorf("filereader: openCVSFile: %w", err)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fail(ErrFileNotFound)
+	}
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return fail(err)
+	}
+
+	if fileInfo.Size() == 0 {
+		return fail(ErrFileIsEmpty)
+	}
+
+	return file, nil
+}
+
+// stdinTransport reads the standard input. As it can only be read once, while transactions are iterated several
+// times, it is kept in memory the first time it is opened, up to DefaultMaxObjectSize. It has no checksum, so that
+// piped files are not checked for duplicates.
+type stdinTransport struct {
+	once    sync.Once
+	content []byte
+	err     error
+}
+
+func (transport *stdinTransport) Open(context.Context, string, Config) (Source, error) {
+	transport.once.Do(func() {
+		transport.content, transport.err = io.ReadAll(io.LimitReader(os.Stdin, DefaultMaxObjectSize+1))
+		if transport.err == nil && len(transport.content) > DefaultMaxObjectSize {
+			transport.err = fmt.Errorf("%w: more than %d bytes", ErrObjectTooLarge, DefaultMaxObjectSize)
+		}
+	})
+
+	if transport.err != nil {
+		return Source{}, transport.err
+	}
+
+	return Source{ReadCloser: memoryFile{Reader: bytes.NewReader(transport.content)}}, nil
+}
+
+func (*stdinTransport) Checksum(context.Context, string, Config) (string, error) {
+	return "", nil
+}
+
+func (file localFile) Size() int64 {
+	return file.size
+}
+
+func (memoryFile) Close() error {
+	return nil
+}
+
+// memberTransport opens a member of the zip archives another transport opens, for archives whose members are read
+// separately. Each member is guarded against decompression bombs on its own.
+type memberTransport struct {
+	archive Transport
+	member  string
+}
+
+func (transport memberTransport) Open(ctx context.Context, uri string, config Config) (Source, error) {
+	fail := func(err error) (Source, error) {
+		return Source{}, fmt.Errorf("filereader: memberTransport: Open %s: %w", transport.member, err)
+	}
+
+	source, err := transport.archive.Open(ctx, uri, config)
+	if err != nil {
+		return fail(err)
+	}
+
+	archive, size, err := openZip(source, source)
+	if err != nil {
+		source.Close()
+		return fail(err)
+	}
+
+	index := slices.IndexFunc(archive.File, func(file *zip.File) bool { return file.Name == transport.member })
+	if index < 0 {
+		source.Close()
+		return fail(ErrFileNotFound)
+	}
+
+	content, err := openZipMember(archive.File[index], size, &decompressionGuard{config: config.Compression})
+	if err != nil {
+		source.Close()
+		return fail(err)
+	}
+
+	closeBoth := func() error {
+		return errors.Join(content.Close(), source.Close())
+	}
+
+	return Source{ReadCloser: readCloser{Reader: content, close: closeBoth}, Name: transport.member}, nil
+}
+
+// Checksum fingerprints the member by the checksum of the archive and its name, leaving the members of archives
+// without a checksum out of the duplicate check as well.
+func (transport memberTransport) Checksum(ctx context.Context, uri string, config Config) (string, error) {
+	checksum, err := transport.archive.Checksum(ctx, uri, config)
+	if err != nil || checksum == "" {
+		return "", err
+	}
+
+	return checksum + "#" + transport.member, nil
+}
@@ -0,0 +1,147 @@
+package filereader
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"iter"
+	"os"
+	"strconv"
+)
+
+type (
+	// RowError describes why a row of the file is invalid. It unwraps to the sentinel of the failure,
+	// e.g. ErrInvalidAmount, so errors.Is keeps working on it.
+	RowError struct {
+		// File is the member of the archive the row was read from. It is empty for files that are not archives.
+		File string
+		// Line is the line of the file where the row starts, the header being line 1.
+		Line int
+		// Column is the name of the invalid column. It is empty when the row as a whole is malformed.
+		Column string
+		// Value is the raw content of the invalid column, or of the whole row when Column is empty.
+		Value string
+		Err   error
+	}
+
+	// ValidationReport is the result of scanning a whole file without stopping at the first invalid row.
+	ValidationReport struct {
+		Rows   int
+		Errors []*RowError
+	}
+
+	// InvalidRowPolicy decides what happens to invalid rows while reading transactions.
+	InvalidRowPolicy int
+
+	quarantine struct {
+		path   string
+		file   *os.File
+		writer *csv.Writer
+	}
+)
+
+const (
+	// RejectFile stops reading at the first invalid row and fails.
+	RejectFile InvalidRowPolicy = iota
+	// SkipInvalidRows leaves invalid rows out and keeps reading.
+	SkipInvalidRows
+	// QuarantineInvalidRows leaves invalid rows out and writes them, along with their errors, to Config.QuarantinePath.
+	QuarantineInvalidRows
+)
+
+func (err *RowError) Error() string {
+	location := fmt.Sprintf("line %d", err.Line)
+	if err.File != "" {
+		location = err.File + ": " + location
+	}
+
+	if err.Column == "" {
+		return fmt.Sprintf("%s: %q: %v", location, err.Value, err.Err)
+	}
+
+	return fmt.Sprintf("%s, column %s: %q: %v", location, err.Column, err.Value, err.Err)
+}
+
+func (err *RowError) Unwrap() error {
+	return err.Err
+}
+
+func (report ValidationReport) Valid() bool {
+	return len(report.Errors) == 0
+}
+
+func validate(ctx context.Context, rows iter.Seq2[Row, error]) (ValidationReport, error) {
+	report := ValidationReport{}
+
+	for row, err := range rows {
+		if err == nil {
+			err = ctx.Err()
+		}
+
+		if err != nil {
+			return ValidationReport{}, fmt.Errorf("filereader: validate: %w", err)
+		}
+
+		report.Rows++
+		report.Errors = append(report.Errors, row.Errors...)
+	}
+
+	return report, nil
+}
+
+func newQuarantine(config Config) *quarantine {
+	return &quarantine{path: config.QuarantinePath}
+}
+
+// add writes the raw row prefixed by its line and errors. The file is only created with the first invalid row.
+func (q *quarantine) add(row Row) error {
+	fail := func(err error) error {
+		return fmt.Errorf("filereader: quarantine: add: %w: %w", ErrQuarantine, err)
+	}
+
+	if q.writer == nil {
+		file, err := os.Create(q.path)
+		if err != nil {
+			return fail(err)
+		}
+
+		q.file = file
+		q.writer = csv.NewWriter(file)
+
+		if errWrite := q.writer.Write(append([]string{"line", "error"}, row.Header...)); errWrite != nil {
+			return fail(errWrite)
+		}
+	}
+
+	record := append([]string{strconv.Itoa(row.Line), row.err().Error()}, row.Fields...)
+	if err := q.writer.Write(record); err != nil {
+		return fail(err)
+	}
+
+	return nil
+}
+
+func (q *quarantine) close() error {
+	if q.file == nil {
+		return nil
+	}
+
+	fail := func(err error) error {
+		return fmt.Errorf("filereader: quarantine: close: %w: %w", ErrQuarantine, err)
+	}
+
+	q.writer.Flush()
+	errFlush := q.writer.Error()
+	errClose := q.file.Close()
+	q.file, q.writer = nil, nil
+
+	if errFlush != nil {
+		return fail(errFlush)
+	}
+
+	if errClose != nil {
+		return fail(errClose)
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package filereader_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stori/adapters/filereader"
+	"stori/test"
+)
+
+func TestValidate_WhenSeveralInvalidRows_ReportsEveryError(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := newReader(t, "testdata/several_invalid_rows.csv", filereader.Config{})
+
+	// Act
+	report, err := sut.Validate(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, report.Valid())
+	assert.Equal(t, 5, report.Rows)
+	require.Len(t, report.Errors, 4)
+
+	assert.Equal(t, 3, report.Errors[0].Line)
+	assert.Equal(t, "transaction", report.Errors[0].Column)
+	assert.Equal(t, "abc", report.Errors[0].Value)
+	require.ErrorIs(t, report.Errors[0], filereader.ErrInvalidAmount)
+
+	assert.Equal(t, 4, report.Errors[1].Line)
+	assert.Empty(t, report.Errors[1].Column)
+	require.ErrorIs(t, report.Errors[1], filereader.ErrInvalidFile)
+
+	assert.Equal(t, 5, report.Errors[2].Line)
+	assert.Equal(t, "id", report.Errors[2].Column)
+	require.ErrorIs(t, report.Errors[2], filereader.ErrInvalidID)
+
+	assert.Equal(t, 5, report.Errors[3].Line)
+	assert.Equal(t, "date", report.Errors[3].Column)
+	assert.Equal(t, "13/40", report.Errors[3].Value)
+	require.ErrorIs(t, report.Errors[3], filereader.ErrInvalidDateFormat)
+}
+
+func TestValidate_WhenValidFile_EmptyReport(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := newReader(t, "testdata/several_transactions.csv", filereader.Config{})
+
+	// Act
+	report, err := sut.Validate(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, report.Valid())
+	assert.Equal(t, 4, report.Rows)
+}
+
+func TestReadTransactions_WhenRejectFile_ErrorWithLine(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := newReader(t, "testdata/several_invalid_rows.csv", filereader.Config{})
+
+	// Act
+	_, err := sut.ReadTransactions(context.Background())
+
+	// Assert
+	require.Error(t, err)
+	require.ErrorIs(t, err, filereader.ErrInvalidAmount)
+
+	var rowErr *filereader.RowError
+	require.ErrorAs(t, err, &rowErr)
+	assert.Equal(t, 3, rowErr.Line)
+}
+
+func TestReadTransactions_WhenSkipInvalidRows_OnlyValidRows(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := newReader(t, "testdata/several_invalid_rows.csv",
+		filereader.Config{InvalidRows: filereader.SkipInvalidRows})
+
+	// Act
+	transactions, err := sut.ReadTransactions(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, 0, transactions[0].ID)
+	assert.Equal(t, 4, transactions[1].ID)
+}
+
+func TestReadTransactions_WhenQuarantineInvalidRows_WritesThemToFile(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	quarantinePath := filepath.Join(t.TempDir(), "quarantine.csv")
+	sut := newReader(t, "testdata/several_invalid_rows.csv", filereader.Config{
+		InvalidRows:    filereader.QuarantineInvalidRows,
+		QuarantinePath: quarantinePath,
+	})
+
+	// Act
+	transactions, err := sut.ReadTransactions(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, transactions, 2)
+
+	content, err := os.ReadFile(quarantinePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "line,error,id,date,transaction\n")
+	assert.Contains(t, string(content), "3,\"line 3, column transaction: \"\"abc\"\": invalid amount\",1,7/28,abc\n")
+	assert.Contains(t, string(content), "\n5,")
+}
+
+func TestReadTransactions_WhenQuarantineCannotBeCreated_Error(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := newReader(t, "testdata/several_invalid_rows.csv", filereader.Config{
+		InvalidRows:    filereader.QuarantineInvalidRows,
+		QuarantinePath: filepath.Join(t.TempDir(), "missing", "quarantine.csv"),
+	})
+
+	// Act
+	_, err := sut.ReadTransactions(context.Background())
+
+	// Assert
+	require.Error(t, err)
+	assert.ErrorIs(t, err, filereader.ErrQuarantine)
+}
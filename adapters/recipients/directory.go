@@ -0,0 +1,84 @@
+package recipients
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	accountColumn = "account"
+	emailColumn   = "email"
+)
+
+// Directory maps accounts to the email address their summary is sent to.
+type Directory struct {
+	emails map[string]string
+}
+
+func New(emails map[string]string) Directory {
+	return Directory{emails: emails}
+}
+
+// Load reads a directory from a CSV file with an "account,email" header, in any order. Other columns are ignored.
+func Load(path string) (Directory, error) {
+	fail := func(err error) (Directory, error) {
+		return Directory{}, fmt.Errorf("recipients: Load: %w", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fail(fmt.Errorf("%w: %w", ErrFileNotFound, err))
+	}
+
+	defer file.Close()
+
+	csvReader := csv.NewReader(file)
+	header, err := csvReader.Read()
+	if err != nil {
+		return fail(fmt.Errorf("%w: %w", ErrInvalidFile, err))
+	}
+
+	account, email := columnIndex(header, accountColumn), columnIndex(header, emailColumn)
+	if account < 0 || email < 0 {
+		return fail(fmt.Errorf("%w: the header must have %s and %s columns", ErrInvalidFile, accountColumn, emailColumn))
+	}
+
+	emails := make(map[string]string)
+	for {
+		record, errRead := csvReader.Read()
+		if errors.Is(errRead, io.EOF) {
+			break
+		}
+
+		if errRead != nil {
+			return fail(fmt.Errorf("%w: %w", ErrInvalidFile, errRead))
+		}
+
+		emails[strings.TrimSpace(record[account])] = strings.TrimSpace(record[email])
+	}
+
+	return New(emails), nil
+}
+
+func (directory Directory) Email(account string) (string, error) {
+	email, ok := directory.emails[account]
+	if !ok {
+		return "", fmt.Errorf("recipients: Directory: Email %q: %w", account, ErrUnknownAccount)
+	}
+
+	return email, nil
+}
+
+func columnIndex(header []string, name string) int {
+	for i, column := range header {
+		if strings.EqualFold(strings.TrimSpace(column), name) {
+			return i
+		}
+	}
+
+	return -1
+}
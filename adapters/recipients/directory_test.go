@@ -0,0 +1,71 @@
+package recipients_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stori/adapters/recipients"
+	"stori/test"
+)
+
+func TestLoad_WhenValidFile_EmailsByAccount(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut, err := recipients.Load("testdata/recipients.csv")
+	require.NoError(t, err)
+
+	// Act
+	john, errJohn := sut.Email("ACC-1")
+	jane, errJane := sut.Email("ACC-2")
+
+	// Assert
+	require.NoError(t, errJohn)
+	require.NoError(t, errJane)
+	assert.Equal(t, "john.doe@stori.com", john)
+	assert.Equal(t, "jane.doe@stori.com", jane)
+}
+
+func TestLoad_WhenFileErrors(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		path string
+		err  error
+	}{
+		{name: "file does not exist", path: "testdata/non-existent-file.csv", err: recipients.ErrFileNotFound},
+		{name: "missing email column", path: "testdata/invalid_header.csv", err: recipients.ErrInvalidFile},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			_, err := recipients.Load(tc.path)
+
+			// Assert
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestEmail_WhenUnknownAccount_Error(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	sut := recipients.New(map[string]string{"ACC-1": "john.doe@stori.com"})
+
+	// Act
+	_, err := sut.Email("ACC-2")
+
+	// Assert
+	require.Error(t, err)
+	assert.ErrorIs(t, err, recipients.ErrUnknownAccount)
+}
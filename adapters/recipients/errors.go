@@ -0,0 +1,9 @@
+package recipients
+
+import "errors"
+
+var ErrFileNotFound = errors.New("file not found")
+var ErrInvalidFile = errors.New("invalid file")
+var ErrUnknownAccount = errors.New("unknown account")
+var ErrNoRecipient = errors.New("no recipient found")
+var ErrObjectLookup = errors.New("error looking up the object")
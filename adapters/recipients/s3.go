@@ -0,0 +1,110 @@
+package recipients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+
+	"stori/adapters/filereader"
+)
+
+// emailKey names the user metadata (x-amz-meta-email) and the tag holding the recipient of an object.
+const emailKey = "email"
+
+// S3Objects finds the email address the summary of an S3 object is sent to. It is, in order of precedence:
+//   - the "email" user metadata of the object, i.e. its x-amz-meta-email header,
+//   - the "email" tag of the object,
+//   - a segment of its key that is an email address, the file name being taken without its extensions,
+//     e.g. "statements/john.doe@stori.com/2024-07.csv" or "statements/john.doe@stori.com.csv.gz".
+type S3Objects struct {
+	client s3iface.S3API
+}
+
+func NewS3Objects(client s3iface.S3API) S3Objects {
+	return S3Objects{client: client}
+}
+
+func (objects S3Objects) Email(ctx context.Context, bucket, key string) (string, error) {
+	fail := func(err error) (string, error) {
+		return "", fmt.Errorf("recipients: S3Objects: Email s3://%s/%s: %w", bucket, key, err)
+	}
+
+	head, err := objects.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fail(fmt.Errorf("%w: %w", ErrObjectLookup, err))
+	}
+
+	for name, value := range head.Metadata {
+		if strings.EqualFold(name, emailKey) && aws.StringValue(value) != "" {
+			return aws.StringValue(value), nil
+		}
+	}
+
+	// Reading tags takes a permission of its own: when it is missing, or the tags cannot be read for another
+	// reason, the key may still tell the recipient. Only an object gone in the meantime stops the lookup.
+	tagging, err := objects.client.GetObjectTaggingWithContext(ctx, &s3.GetObjectTaggingInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil && isNotFound(err) {
+		return fail(fmt.Errorf("%w: %w", ErrObjectLookup, err))
+	}
+
+	if err == nil {
+		for _, tag := range tagging.TagSet {
+			if strings.EqualFold(aws.StringValue(tag.Key), emailKey) && aws.StringValue(tag.Value) != "" {
+				return aws.StringValue(tag.Value), nil
+			}
+		}
+	}
+
+	if email, ok := emailInKey(key); ok {
+		return email, nil
+	}
+
+	return fail(ErrNoRecipient)
+}
+
+// emailInKey finds the email address among the segments of the key, the file name being taken without the extension
+// of its compression, if any, and then that of its format, e.g. john.doe@stori.com for john.doe@stori.com.csv.gz.
+func emailInKey(key string) (string, bool) {
+	segments := strings.Split(key, "/")
+	last := len(segments) - 1
+	name := filereader.StripCompression(segments[last])
+	segments[last] = strings.TrimSuffix(name, path.Ext(name))
+
+	for _, segment := range segments {
+		address, err := mail.ParseAddress(segment)
+		if err == nil && address.Address == segment {
+			return segment, true
+		}
+	}
+
+	return "", false
+}
+
+// isNotFound tells whether the error is S3 telling the object or its bucket does not exist.
+func isNotFound(err error) bool {
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) {
+		return false
+	}
+
+	switch awsErr.Code() {
+	case s3.ErrCodeNoSuchKey, s3.ErrCodeNoSuchBucket, "NotFound":
+		return true
+	default:
+		return false
+	}
+}
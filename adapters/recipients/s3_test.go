@@ -0,0 +1,92 @@
+package recipients_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stori/adapters/recipients"
+	"stori/test"
+)
+
+const bucket = "statements"
+
+func TestS3ObjectsEmail(t *testing.T) {
+	t.Parallel()
+
+	fake := test.NewFakeS3(t)
+	fake.Put(bucket, "metadata.csv", test.FakeObject{
+		Metadata: map[string]string{"email": "metadata@stori.com"},
+		Tags:     map[string]string{"email": "tag@stori.com"},
+	})
+	fake.Put(bucket, "tagged.csv", test.FakeObject{Tags: map[string]string{"email": "tag@stori.com"}})
+	fake.Put(bucket, "daily/folder@stori.com/2024-07.csv", test.FakeObject{})
+	fake.Put(bucket, "daily/file@stori.com.csv", test.FakeObject{})
+	fake.Put(bucket, "daily/denied@stori.com.csv", test.FakeObject{TaggingDenied: true})
+	fake.Put(bucket, "daily/compressed@stori.com.csv.gz", test.FakeObject{})
+
+	testCases := []struct {
+		name     string
+		key      string
+		expected string
+	}{
+		{name: "metadata first", key: "metadata.csv", expected: "metadata@stori.com"},
+		{name: "then tags", key: "tagged.csv", expected: "tag@stori.com"},
+		{name: "then a folder of the key", key: "daily/folder@stori.com/2024-07.csv", expected: "folder@stori.com"},
+		{name: "then the file name", key: "daily/file@stori.com.csv", expected: "file@stori.com"},
+		{name: "key when tags are denied", key: "daily/denied@stori.com.csv", expected: "denied@stori.com"},
+		{
+			name: "file name without compression", key: "daily/compressed@stori.com.csv.gz",
+			expected: "compressed@stori.com",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			sut := recipients.NewS3Objects(fake.Client())
+
+			// Act
+			email, err := sut.Email(context.Background(), bucket, tc.key)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, email)
+		})
+	}
+}
+
+func TestS3ObjectsEmail_WhenNoRecipient_Error(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	fake := test.NewFakeS3(t)
+	fake.Put(bucket, "daily/2024-07.csv", test.FakeObject{Metadata: map[string]string{"owner": "finance"}})
+	sut := recipients.NewS3Objects(fake.Client())
+
+	// Act
+	_, err := sut.Email(context.Background(), bucket, "daily/2024-07.csv")
+
+	// Assert
+	require.Error(t, err)
+	assert.ErrorIs(t, err, recipients.ErrNoRecipient)
+}
+
+func TestS3ObjectsEmail_WhenObjectDoesNotExist_Error(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	fake := test.NewFakeS3(t)
+	sut := recipients.NewS3Objects(fake.Client())
+
+	// Act
+	_, err := sut.Email(context.Background(), bucket, "missing@stori.com.csv")
+
+	// Assert
+	require.Error(t, err)
+	assert.ErrorIs(t, err, recipients.ErrObjectLookup)
+}
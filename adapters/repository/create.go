@@ -1,90 +1,326 @@
 package repository
 
 import (
+	"context"
+	"crypto/rand"
 	"database/sql"
 	"errors"
 	"fmt"
 	"log"
+	"slices"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 
 	"stori/accountsummary"
+	"stori/model"
 )
 
-func (repo Repository) Create(execution accountsummary.Execution) error {
+// transactionColumns are the columns COPY expects for every transaction, in order.
+var transactionColumns = []string{ //nolint:gochecknoglobals // Read only
+	"execution_id", "source_id", "date", "amount", "file_path", "account", "attributes",
+}
+
+// Create returns the id of the summary stored, which is 0 when there is no database to store it in.
+func (repo Repository) Create(ctx context.Context, execution accountsummary.Execution) (int64, error) {
 	if repo.DB != nil {
-		return repo.create(execution)
+		return repo.create(ctx, execution)
 	}
 
-	return nil
+	return 0, nil
 }
 
-func (repo Repository) create(execution accountsummary.Execution) error {
-	fail := func(err error) error {
-		return fmt.Errorf("repository: Repository: Create: %w", err)
+// create stores the execution, or else records it as failed.
+func (repo Repository) create(ctx context.Context, execution accountsummary.Execution) (int64, error) {
+	fail := func(err error) (int64, error) {
+		return 0, fmt.Errorf("repository: Repository: Create: %w", err)
+	}
+
+	executionID, err := newExecutionID()
+	if err != nil {
+		return fail(err)
 	}
 
-	tx, errX := repo.DB.Beginx()
-	if errX != nil {
-		return fail(fmt.Errorf("failed creating a db transaction: %w", errX))
+	summaryID, err := repo.store(ctx, execution, executionID)
+	if err != nil {
+		repo.recordFailure(ctx, execution, executionID)
+		return fail(err)
+	}
+
+	return summaryID, nil
+}
+
+// store stores the execution, its summary and its transactions in a single db transaction, so that an execution is
+// never stored partially. The db transaction is rolled back when the context is done before it is committed.
+func (repo Repository) store(
+	ctx context.Context, execution accountsummary.Execution, executionID string,
+) (int64, error) {
+	fail := func(err error) (int64, error) {
+		return 0, fmt.Errorf("repository: Repository: store: %w", err)
+	}
+
+	tx, err := repo.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fail(fmt.Errorf("failed creating a db transaction: %w", err))
 	}
 
 	defer func(tx *sqlx.Tx) {
 		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
-			log.Fatal(fail(err))
+			log.Fatal(fmt.Errorf("repository: Repository: store: %w", err))
 		}
 	}(tx)
 
-	accountDB := repo.AccountSummaryFromModel(execution.AccountSummary, execution.FilePath)
-	if err := repo.createAccountSummary(accountDB); err != nil {
+	if err = repo.createExecution(ctx, tx, repo.ExecutionFromModel(execution, executionID)); err != nil {
+		return fail(fmt.Errorf("failed creating an execution: %w", err))
+	}
+
+	accountDB := repo.AccountSummaryFromModel(execution.AccountSummary, execution.FilePath, executionID)
+	summaryID, err := repo.createAccountSummary(ctx, tx, accountDB)
+	if err != nil {
 		return fail(fmt.Errorf("failed creating an account summary: %w", err))
 	}
 
-	transactionsDB := make([]Transaction, len(execution.Transactions))
-	for i, transaction := range execution.Transactions {
-		transactionsDB[i] = repo.TransactionFromModel(transaction, execution.FilePath)
+	if execution.QueueEmail {
+		if err = repo.enqueueEmail(ctx, tx, summaryID); err != nil {
+			return fail(fmt.Errorf("failed queueing the email: %w", err))
+		}
 	}
 
-	if err := repo.createTransactions(transactionsDB); err != nil {
+	if err = repo.createTransactions(ctx, tx, execution, executionID); err != nil {
 		return fail(fmt.Errorf("failed creating transactions: %w", err))
 	}
 
-	if err := tx.Commit(); err != nil {
+	if err = repo.finishExecution(ctx, tx, executionID); err != nil {
+		return fail(fmt.Errorf("failed finishing the execution: %w", err))
+	}
+
+	if err = tx.Commit(); err != nil {
 		return fail(fmt.Errorf("failed committing the db transaction: %w", err))
 	}
 
+	return summaryID, nil
+}
+
+func (repo Repository) createExecution(ctx context.Context, tx *sqlx.Tx, execution Execution) error {
+	fail := func(err error) error {
+		return fmt.Errorf("repository: Repository: createExecution: %w", err)
+	}
+
+	query := `insert into execution(id, source_uri, checksum, status, started_at)
+values (:id, :source_uri, :checksum, :status, :started_at)`
+
+	if _, err := tx.NamedExecContext(ctx, query, execution); err != nil {
+		return fail(err)
+	}
+
 	return nil
 }
 
-func (repo Repository) createAccountSummary(summary AccountSummary) error {
+// finishExecution completes the execution, once its summary and its transactions are stored.
+func (repo Repository) finishExecution(ctx context.Context, tx *sqlx.Tx, executionID string) error {
 	fail := func(err error) error {
-		return fmt.Errorf("repository: Repository: createAccountSummary: %w", err)
+		return fmt.Errorf("repository: Repository: finishExecution: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `update execution set status = $1, finished_at = $2 where id = $3`,
+		model.ExecutionCompleted, time.Now().UTC(), executionID); err != nil {
+		return fail(err)
+	}
+
+	return nil
+}
+
+// recordFailure stores the execution as failed, without its summary nor its transactions, as its db transaction was
+// rolled back. Interrupted executions are recorded too. It is only logged when it fails, so that the error of the
+// execution is the one returned.
+func (repo Repository) recordFailure(ctx context.Context, execution accountsummary.Execution, executionID string) {
+	failed := repo.ExecutionFromModel(execution, executionID)
+	failed.Status = model.ExecutionFailed
+	failed.FinishedAt = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+
+	query := `insert into execution(id, source_uri, checksum, status, started_at, finished_at)
+values (:id, :source_uri, :checksum, :status, :started_at, :finished_at)`
+
+	if _, err := repo.DB.NamedExecContext(context.WithoutCancel(ctx), query, failed); err != nil {
+		log.Printf("repository: Repository: recordFailure: %v", err)
+	}
+}
+
+func (repo Repository) createAccountSummary(ctx context.Context, tx *sqlx.Tx, summary AccountSummary) (int64, error) {
+	fail := func(err error) (int64, error) {
+		return 0, fmt.Errorf("repository: Repository: createAccountSummary: %w", err)
 	}
 
 	query := `insert into account_summary(
-email, total_balance, average_debit_amount, average_credit_amount, transactions_per_month, file_path)
-values (:email, :total_balance, :average_debit_amount, :average_credit_amount, :transactions_per_month, :file_path)`
+execution_id, email, account, total_balance, average_debit_amount, average_credit_amount, transactions_per_month,
+file_path, minimum_balance, minimum_balance_date, maximum_balance, maximum_balance_date, daily_balances,
+monthly_breakdown)
+values (:execution_id, :email, :account, :total_balance, :average_debit_amount, :average_credit_amount,
+:transactions_per_month, :file_path, :minimum_balance, :minimum_balance_date, :maximum_balance,
+:maximum_balance_date, :daily_balances, :monthly_breakdown)
+returning id`
 
-	_, err := repo.DB.NamedExec(query, summary)
+	stmt, err := tx.PrepareNamedContext(ctx, query)
 	if err != nil {
 		return fail(err)
 	}
 
+	defer stmt.Close()
+
+	var id int64
+	if err = stmt.GetContext(ctx, &id, summary); err != nil {
+		return fail(err)
+	}
+
+	return id, nil
+}
+
+// enqueueEmail queues the email of the summary in the outbox, to be sent once the db transaction is committed.
+func (repo Repository) enqueueEmail(ctx context.Context, tx *sqlx.Tx, summaryID int64) error {
+	if _, err := tx.ExecContext(ctx, `insert into email_outbox(account_summary_id) values ($1)`, summaryID); err != nil {
+		return fmt.Errorf("repository: Repository: enqueueEmail: %w", err)
+	}
+
 	return nil
 }
 
-func (repo Repository) createTransactions(transactions []Transaction) error {
+// createTransactions consumes the transactions stream without holding it in memory. Transactions are buffered up to
+// the copy threshold: a stream that ends before it is inserted in batches, while a longer one is loaded with COPY,
+// starting with the buffered transactions. Either way, rows go through the db transaction of Create.
+func (repo Repository) createTransactions(
+	ctx context.Context, tx *sqlx.Tx, execution accountsummary.Execution, executionID string,
+) error {
 	fail := func(err error) error {
 		return fmt.Errorf("repository: Repository: createTransactions: %w", err)
 	}
 
-	query := `insert into transaction(date, amount, file_path)
-values (:date, :amount, :file_path)`
+	threshold, batchSize := repo.copyThreshold(), repo.batchSize()
 
-	_, err := repo.DB.NamedExec(query, transactions)
+	var copier *sql.Stmt
+	defer func() {
+		// The copy must end before the db transaction is rolled back, as no other statement can run meanwhile.
+		if copier != nil {
+			_ = copier.Close()
+		}
+	}()
+
+	pending := make([]Transaction, 0, batchSize)
+	for transaction, err := range execution.Transactions {
+		if err != nil {
+			return fail(err)
+		}
+
+		row := repo.TransactionFromModel(transaction, execution.FilePath, executionID)
+		if copier != nil {
+			if errCopy := copyTransactions(ctx, copier, row); errCopy != nil {
+				return fail(errCopy)
+			}
+
+			continue
+		}
+
+		pending = append(pending, row)
+		switch {
+		case threshold > 0 && len(pending) >= threshold:
+			if copier, err = startCopy(ctx, tx); err != nil {
+				return fail(err)
+			}
+
+			if errCopy := copyTransactions(ctx, copier, pending...); errCopy != nil {
+				return fail(errCopy)
+			}
+
+			pending = nil
+		case threshold <= 0 && len(pending) >= batchSize:
+			if errInsert := insertTransactions(ctx, tx, pending, batchSize); errInsert != nil {
+				return fail(errInsert)
+			}
+
+			pending = pending[:0]
+		}
+	}
+
+	if copier != nil {
+		if err := finishCopy(ctx, copier); err != nil {
+			return fail(err)
+		}
+
+		return nil
+	}
+
+	if err := insertTransactions(ctx, tx, pending, batchSize); err != nil {
+		return fail(err)
+	}
+
+	return nil
+}
+
+// insertTransactions inserts the transactions with one statement per batch.
+func insertTransactions(ctx context.Context, tx *sqlx.Tx, transactions []Transaction, batchSize int) error {
+	fail := func(err error) error {
+		return fmt.Errorf("repository: insertTransactions: %w", err)
+	}
+
+	query := `insert into transaction(execution_id, source_id, date, amount, file_path, account, attributes)
+values (:execution_id, :source_id, :date, :amount, :file_path, :account, :attributes)`
+
+	for batch := range slices.Chunk(transactions, batchSize) {
+		if _, err := tx.NamedExecContext(ctx, query, batch); err != nil {
+			return fail(err)
+		}
+	}
+
+	return nil
+}
+
+// startCopy prepares a COPY FROM STDIN of transactions. Rows are sent with copyTransactions and loaded by finishCopy.
+func startCopy(ctx context.Context, tx *sqlx.Tx) (*sql.Stmt, error) {
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("transaction", transactionColumns...))
 	if err != nil {
+		return nil, fmt.Errorf("repository: startCopy: %w", err)
+	}
+
+	return stmt, nil
+}
+
+func copyTransactions(ctx context.Context, stmt *sql.Stmt, transactions ...Transaction) error {
+	for _, transaction := range transactions {
+		_, err := stmt.ExecContext(ctx, transaction.ExecutionID, transaction.SourceID, transaction.Date, transaction.Amount,
+			transaction.FilePath, transaction.Account, transaction.Attributes)
+		if err != nil {
+			return fmt.Errorf("repository: copyTransactions: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func finishCopy(ctx context.Context, stmt *sql.Stmt) error {
+	fail := func(err error) error {
+		return fmt.Errorf("repository: finishCopy: %w", err)
+	}
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return fail(err)
+	}
+
+	if err := stmt.Close(); err != nil {
 		return fail(err)
 	}
 
 	return nil
 }
+
+// newExecutionID generates a random (version 4) UUID.
+func newExecutionID() (string, error) {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		return "", fmt.Errorf("repository: newExecutionID: %w", err)
+	}
+
+	uuid[6] = (uuid[6] & 0x0f) | 0x40
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:]), nil
+}
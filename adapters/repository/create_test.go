@@ -1,6 +1,9 @@
 package repository_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -13,6 +16,7 @@ import (
 	"stori/adapters/repository"
 	"stori/model"
 	"stori/test"
+	"stori/transactions"
 )
 
 func TestCreate(t *testing.T) {
@@ -24,54 +28,298 @@ func TestCreate(t *testing.T) {
 	sut := repository.New(dbx)
 	summary := model.AccountSummary{
 		Email:               "john.doe@example.com",
+		Account:             "ACC-1",
 		TotalBalance:        decimal.MustParse("100.0"),
 		AverageDebitAmount:  decimal.MustParse("50.0"),
 		AverageCreditAmount: decimal.MustParse("150.0"),
-		TransactionsPerMonth: map[time.Month]int{ //nolint:exhaustive
-			time.January:  1,
-			time.February: 1,
+		TransactionsPerMonth: map[model.YearMonth]int{
+			{Year: 2023, Month: time.January}: 1,
+			{Year: 2024, Month: time.January}: 1,
+		},
+		MinimumBalance:     decimal.MustNew(150, 0),
+		MinimumBalanceDate: time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC),
+		MaximumBalance:     decimal.MustNew(200, 0),
+		MaximumBalanceDate: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+		DailyBalances: []model.DailyBalance{
+			{Date: time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC), Balance: decimal.MustNew(150, 0)},
+			{Date: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), Balance: decimal.MustNew(200, 0)},
+		},
+		MonthlyBreakdown: []model.PeriodBreakdown{
+			{
+				Period:              model.YearMonth{Year: 2023, Month: time.January},
+				CreditCount:         1,
+				TotalCredit:         decimal.MustNew(150, 0),
+				TotalDebit:          decimal.Zero,
+				NetFlow:             decimal.MustNew(150, 0),
+				AverageCreditAmount: decimal.MustNew(150, 0),
+				AverageDebitAmount:  decimal.Zero,
+			},
+			{
+				Period:              model.YearMonth{Year: 2024, Month: time.January},
+				CreditCount:         1,
+				TotalCredit:         decimal.MustNew(50, 0),
+				TotalDebit:          decimal.Zero,
+				NetFlow:             decimal.MustNew(50, 0),
+				AverageCreditAmount: decimal.MustNew(50, 0),
+				AverageDebitAmount:  decimal.Zero,
+			},
 		},
 	}
 	execution := accountsummary.Execution{
 		AccountSummary: summary,
-		Transactions: []model.Transaction{
+		Transactions: transactions.Seq([]model.Transaction{
 			{
 				ID:     1,
-				Date:   time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+				Date:   time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC),
 				Amount: decimal.MustNew(150, 0),
 			},
 			{
 				ID:     2,
-				Date:   time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+				Date:   time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
 				Amount: decimal.MustNew(50, 0),
 			},
-		},
-		FilePath: "test.csv",
+		}),
+		FilePath:   "test.csv",
+		Checksum:   "sha256:test",
+		StartedAt:  time.Now().UTC(),
+		QueueEmail: true,
 	}
 
 	// Act
-	err := sut.Create(execution)
+	summaryID, err := sut.Create(context.Background(), execution)
 
 	// Assert
 	require.NoError(t, err)
+	assert.NotZero(t, summaryID)
 
 	respSummaries := []repository.AccountSummary{}
 	err = dbx.Select(&respSummaries,
-		`select email, total_balance,
-				average_debit_amount, average_credit_amount, transactions_per_month, file_path
-				from account_summary`)
+		`select execution_id, email, account, total_balance,
+				average_debit_amount, average_credit_amount, transactions_per_month, file_path,
+				minimum_balance, minimum_balance_date, maximum_balance, maximum_balance_date, daily_balances,
+				monthly_breakdown
+				from account_summary where email = $1`, "john.doe@example.com")
 
 	require.NoError(t, err)
 	require.Len(t, respSummaries, 1)
 
 	res := respSummaries[0]
 	assert.Equal(t, "john.doe@example.com", res.Email)
+	assert.Equal(t, "ACC-1", res.Account.String)
 	assert.Equal(t, decimal.MustParse("100.0"), res.TotalBalance)
 	assert.Equal(t, decimal.MustParse("150.0"), res.AverageCreditAmount)
 	assert.Equal(t, decimal.MustParse("50.0"), res.AverageDebitAmount)
 	assert.Equal(t, "test.csv", res.FilePath)
 
 	require.NotEmpty(t, res.TransactionsPerMonth)
-	assert.Equal(t, 1, res.TransactionsPerMonth[time.January])
-	assert.Equal(t, 1, res.TransactionsPerMonth[time.February])
+	assert.Equal(t, 1, res.TransactionsPerMonth[model.YearMonth{Year: 2023, Month: time.January}])
+	assert.Equal(t, 1, res.TransactionsPerMonth[model.YearMonth{Year: 2024, Month: time.January}])
+
+	assert.True(t, res.MinimumBalance.Valid)
+	assert.Equal(t, 0, decimal.MustNew(150, 0).Cmp(res.MinimumBalance.Decimal))
+	assert.True(t, res.MinimumBalanceDate.Time.Equal(summary.MinimumBalanceDate))
+	assert.Equal(t, 0, decimal.MustNew(200, 0).Cmp(res.MaximumBalance.Decimal))
+	assert.True(t, res.MaximumBalanceDate.Time.Equal(summary.MaximumBalanceDate))
+	assert.Equal(t, repository.DailyBalances(summary.DailyBalances), res.DailyBalances)
+	assert.Equal(t, repository.MonthlyBreakdown(summary.MonthlyBreakdown), res.MonthlyBreakdown)
+
+	executions := []repository.Execution{}
+	err = dbx.Select(&executions,
+		`select id, source_uri, checksum, status, started_at, finished_at from execution where id = $1`,
+		res.ExecutionID)
+
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	assert.Equal(t, "test.csv", executions[0].SourceURI)
+	assert.Equal(t, "sha256:test", executions[0].Checksum.String)
+	assert.Equal(t, model.ExecutionCompleted, executions[0].Status)
+	assert.True(t, executions[0].FinishedAt.Valid)
+
+	respTransactions := []repository.Transaction{}
+	err = dbx.Select(&respTransactions,
+		`select id, execution_id, source_id, date, amount, file_path, account
+				from transaction where execution_id = $1 order by source_id`, res.ExecutionID)
+
+	require.NoError(t, err)
+	require.Len(t, respTransactions, 2)
+	assert.Equal(t, int64(1), respTransactions[0].SourceID.Int64)
+	assert.Equal(t, int64(2), respTransactions[1].SourceID.Int64)
+
+	var outboxStatuses []string
+	err = dbx.Select(&outboxStatuses, `select o.status from email_outbox o
+				join account_summary s on s.id = o.account_summary_id where s.execution_id = $1`, res.ExecutionID)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{model.OutboxPending}, outboxStatuses)
+}
+
+func TestCreate_TransactionsLoadingStrategies(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		config repository.Config
+	}{
+		{name: "below the copy threshold", config: repository.Config{BatchSize: 2}},
+		{name: "above the copy threshold", config: repository.Config{CopyThreshold: 3}},
+		{name: "exactly the copy threshold", config: repository.Config{CopyThreshold: 5}},
+		{name: "copy disabled", config: repository.Config{BatchSize: 2, CopyThreshold: -1}},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			tc.config.DB = sqlx.NewDb(DB, "postgres")
+			sut := &repository.Repository{Config: tc.config}
+			email := fmt.Sprintf("loading.strategy.%d@example.com", i)
+
+			// Act
+			_, err := sut.Create(context.Background(), buildExecution(email, 5))
+
+			// Assert
+			require.NoError(t, err)
+
+			summaries, err := sut.ListSummaries(context.Background(), accountsummary.SummaryFilter{Email: email})
+			require.NoError(t, err)
+			require.Len(t, summaries, 1)
+
+			stored, err := sut.ListTransactions(context.Background(), summaries[0].Execution.ID)
+			require.NoError(t, err)
+			require.Len(t, stored, 5)
+			for day, transaction := range stored {
+				assert.Equal(t, day, transaction.ID)
+				assert.Equal(t, "ACC-1", transaction.Account)
+				assert.Equal(t, 0, decimal.MustNew(int64(day+1), 0).Cmp(transaction.Amount))
+				if day%2 == 0 {
+					assert.Equal(t, map[string]string{"description": fmt.Sprintf("Day \"%d\"\t", day+1)},
+						transaction.Attributes)
+				} else {
+					assert.Nil(t, transaction.Attributes)
+				}
+			}
+		})
+	}
+}
+
+func TestCreate_WhenEmailNotQueued_OutboxEmpty(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	dbx := sqlx.NewDb(DB, "postgres")
+	sut := repository.New(dbx)
+
+	// Act
+	summaryID, err := sut.Create(context.Background(), buildExecution("not.queued@example.com", 2))
+
+	// Assert
+	require.NoError(t, err)
+
+	var queued int
+	err = dbx.Get(&queued, `select count(*) from email_outbox where account_summary_id = $1`, summaryID)
+	require.NoError(t, err)
+	assert.Zero(t, queued)
+}
+
+func TestCreate_WhenStreamFailsWhileCopying_NothingStored(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := &repository.Repository{Config: repository.Config{DB: sqlx.NewDb(DB, "postgres"), CopyThreshold: 2}}
+	email := "failed.copy@example.com"
+	execution := buildExecution(email, 3)
+	streamErr := errors.New("stream failed")
+	transactions := execution.Transactions
+	execution.Transactions = func(yield func(model.Transaction, error) bool) {
+		for transaction, err := range transactions {
+			if !yield(transaction, err) {
+				return
+			}
+		}
+		yield(model.Transaction{}, streamErr)
+	}
+
+	// Act
+	_, err := sut.Create(context.Background(), execution)
+
+	// Assert
+	require.ErrorIs(t, err, streamErr)
+
+	summaries, err := sut.ListSummaries(context.Background(), accountsummary.SummaryFilter{Email: email})
+	require.NoError(t, err)
+	assert.Empty(t, summaries)
+
+	executions := []repository.Execution{}
+	err = sut.DB.Select(&executions, `select id, source_uri, checksum, status, started_at, finished_at
+				from execution where source_uri = $1`, execution.FilePath)
+
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	assert.Equal(t, model.ExecutionFailed, executions[0].Status)
+	assert.True(t, executions[0].FinishedAt.Valid)
+}
+
+func BenchmarkCreate(b *testing.B) {
+	for _, size := range []int{1_000, 25_000, 100_000} {
+		for _, strategy := range []struct {
+			name   string
+			config repository.Config
+		}{
+			{name: "batched inserts", config: repository.Config{CopyThreshold: -1}},
+			{name: "copy", config: repository.Config{CopyThreshold: 1}},
+		} {
+			b.Run(fmt.Sprintf("%s/%d", strategy.name, size), func(b *testing.B) {
+				strategy.config.DB = sqlx.NewDb(DB, "postgres")
+				sut := &repository.Repository{Config: strategy.config}
+
+				for i := 0; i < b.N; i++ {
+					execution := buildExecution(fmt.Sprintf("benchmark.%d@example.com", size), size)
+					if _, err := sut.Create(context.Background(), execution); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// buildExecution has size transactions of the ACC-1 account, one per day from 2024-01-01, with IDs from 0 and
+// amounts from 1.
+func buildExecution(email string, size int) accountsummary.Execution {
+	first := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	return accountsummary.Execution{
+		AccountSummary: model.AccountSummary{
+			Email:               email,
+			Account:             "ACC-1",
+			TotalBalance:        decimal.Zero,
+			AverageDebitAmount:  decimal.Zero,
+			AverageCreditAmount: decimal.Zero,
+			TransactionsPerMonth: map[model.YearMonth]int{
+				{Year: 2024, Month: time.January}: size,
+			},
+		},
+		Transactions: func(yield func(model.Transaction, error) bool) {
+			for i := range size {
+				transaction := model.Transaction{
+					ID:      i,
+					Date:    first.AddDate(0, 0, i),
+					Amount:  decimal.MustNew(int64(i+1), 0),
+					Account: "ACC-1",
+				}
+				if i%2 == 0 {
+					transaction.Attributes = map[string]string{"description": fmt.Sprintf("Day \"%d\"\t", i+1)}
+				}
+				if !yield(transaction, nil) {
+					return
+				}
+			}
+		},
+		FilePath:  email + ".csv",
+		StartedAt: time.Now().UTC(),
+	}
 }
@@ -0,0 +1,6 @@
+package repository
+
+import "errors"
+
+var ErrNoDatabase = errors.New("no database")
+var ErrNotFound = errors.New("not found")
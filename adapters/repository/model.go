@@ -1,33 +1,95 @@
 package repository
 
 import (
+	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
 	"time"
 
 	"github.com/govalues/decimal"
+
+	"stori/model"
 )
 
 type (
+	Execution struct {
+		ID         string         `db:"id"`
+		SourceURI  string         `db:"source_uri"`
+		Checksum   sql.NullString `db:"checksum"`
+		Status     string         `db:"status"`
+		StartedAt  time.Time      `db:"started_at"`
+		FinishedAt sql.NullTime   `db:"finished_at"`
+	}
+
 	Transaction struct {
-		Date     time.Time       `db:"date"`
-		Amount   decimal.Decimal `db:"amount"`
-		FilePath string          `db:"file_path"`
+		ID          int64           `db:"id"`
+		ExecutionID string          `db:"execution_id"`
+		SourceID    sql.NullInt64   `db:"source_id"`
+		Date        time.Time       `db:"date"`
+		Amount      decimal.Decimal `db:"amount"`
+		FilePath    string          `db:"file_path"`
+		Account     sql.NullString  `db:"account"`
+		Attributes  Attributes      `db:"attributes"`
 	}
 
 	AccountSummary struct {
+		ID                   int64           `db:"id"`
+		ExecutionID          string          `db:"execution_id"`
 		Email                string          `db:"email"`
+		Account              sql.NullString  `db:"account"`
 		TotalBalance         decimal.Decimal `db:"total_balance"`
 		AverageDebitAmount   decimal.Decimal `db:"average_debit_amount"`
 		AverageCreditAmount  decimal.Decimal `db:"average_credit_amount"`
 		TransactionsPerMonth TransPerMonth   `db:"transactions_per_month"`
 		FilePath             string          `db:"file_path"`
+		CreatedAt            time.Time       `db:"created_at"`
+		// Balance extremes and daily balances are null for summaries created before they were computed.
+		MinimumBalance     decimal.NullDecimal `db:"minimum_balance"`
+		MinimumBalanceDate sql.NullTime        `db:"minimum_balance_date"`
+		MaximumBalance     decimal.NullDecimal `db:"maximum_balance"`
+		MaximumBalanceDate sql.NullTime        `db:"maximum_balance_date"`
+		DailyBalances      DailyBalances       `db:"daily_balances"`
+		MonthlyBreakdown   MonthlyBreakdown    `db:"monthly_breakdown"`
+	}
+
+	// StoredSummary is an account summary joined with its execution.
+	StoredSummary struct {
+		AccountSummary
+		Execution Execution `db:"execution"`
+	}
+
+	TransPerMonth map[model.YearMonth]int
+
+	// Attributes is stored as a JSON object, e.g. {"description": "Payroll"}, or null when there is none.
+	Attributes map[string]string
+
+	// DailyBalances is stored as a JSON array of end-of-day balances, e.g. [{"date": "2024-01-15", "balance": "10.5"}].
+	DailyBalances []model.DailyBalance
+
+	dailyBalanceJSON struct {
+		Date    string          `json:"date"`
+		Balance decimal.Decimal `json:"balance"`
 	}
 
-	TransPerMonth map[time.Month]int
+	// MonthlyBreakdown is stored as a JSON array with an object per month, e.g.
+	// [{"period": "2024-01", "creditCount": 1, "totalCredit": "10.5", ...}].
+	MonthlyBreakdown []model.PeriodBreakdown
+
+	periodBreakdownJSON struct {
+		Period              model.YearMonth `json:"period"`
+		CreditCount         int             `json:"creditCount"`
+		DebitCount          int             `json:"debitCount"`
+		TotalCredit         decimal.Decimal `json:"totalCredit"`
+		TotalDebit          decimal.Decimal `json:"totalDebit"`
+		NetFlow             decimal.Decimal `json:"netFlow"`
+		AverageCreditAmount decimal.Decimal `json:"averageCreditAmount"`
+		AverageDebitAmount  decimal.Decimal `json:"averageDebitAmount"`
+	}
 )
 
+const dailyBalanceDateLayout = "2006-01-02"
+
 func (tpm TransPerMonth) Value() (driver.Value, error) {
 	return json.Marshal(tpm)
 }
@@ -40,3 +102,114 @@ func (tpm *TransPerMonth) Scan(value interface{}) error {
 
 	return json.Unmarshal(b, &tpm)
 }
+
+// Value is the JSON text of the attributes rather than its bytes, as COPY would send bytes as bytea.
+func (attributes Attributes) Value() (driver.Value, error) {
+	if len(attributes) == 0 {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(map[string]string(attributes))
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+func (attributes *Attributes) Scan(value interface{}) error {
+	if value == nil {
+		*attributes = nil
+		return nil
+	}
+
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, (*map[string]string)(attributes))
+}
+
+func (balances DailyBalances) Value() (driver.Value, error) {
+	if balances == nil {
+		return nil, nil
+	}
+
+	entries := make([]dailyBalanceJSON, len(balances))
+	for i, balance := range balances {
+		entries[i] = dailyBalanceJSON{Date: balance.Date.Format(dailyBalanceDateLayout), Balance: balance.Balance}
+	}
+
+	return json.Marshal(entries)
+}
+
+func (balances *DailyBalances) Scan(value interface{}) error {
+	if value == nil {
+		*balances = nil
+		return nil
+	}
+
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	var entries []dailyBalanceJSON
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return err
+	}
+
+	result := make(DailyBalances, len(entries))
+	for i, entry := range entries {
+		date, err := time.Parse(dailyBalanceDateLayout, entry.Date)
+		if err != nil {
+			return err
+		}
+
+		result[i] = model.DailyBalance{Date: date, Balance: entry.Balance}
+	}
+
+	*balances = result
+
+	return nil
+}
+
+func (breakdown MonthlyBreakdown) Value() (driver.Value, error) {
+	if breakdown == nil {
+		return nil, nil
+	}
+
+	entries := make([]periodBreakdownJSON, len(breakdown))
+	for i, period := range breakdown {
+		entries[i] = periodBreakdownJSON(period)
+	}
+
+	return json.Marshal(entries)
+}
+
+func (breakdown *MonthlyBreakdown) Scan(value interface{}) error {
+	if value == nil {
+		*breakdown = nil
+		return nil
+	}
+
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	var entries []periodBreakdownJSON
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return err
+	}
+
+	result := make(MonthlyBreakdown, len(entries))
+	for i, entry := range entries {
+		result[i] = model.PeriodBreakdown(entry)
+	}
+
+	*breakdown = result
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"stori/model"
+)
+
+type claimedEmail struct {
+	ID               int64 `db:"id"`
+	AccountSummaryID int64 `db:"account_summary_id"`
+	Attempts         int   `db:"attempts"`
+}
+
+// Claim returns up to limit pending emails that are due, oldest first, and postpones them by the lease.
+// Rows locked by a concurrent claim are skipped rather than waited for.
+func (repo Repository) Claim(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxEmail, error) {
+	query := `update email_outbox
+set next_attempt_at = now() + $3 * interval '1 millisecond'
+where id in (
+    select id
+    from email_outbox
+    where status = $1 and next_attempt_at <= now()
+    order by id
+    limit $2
+    for update skip locked)
+returning id, account_summary_id, attempts`
+
+	emails, err := repo.claim(ctx, query, model.OutboxPending, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("repository: Repository: Claim: %w", err)
+	}
+
+	return emails, nil
+}
+
+// ClaimSummary returns the pending email of the summary when it is due, and postpones it by the lease. It returns no
+// email when a concurrent claim holds it.
+func (repo Repository) ClaimSummary(
+	ctx context.Context, summaryID int64, lease time.Duration,
+) ([]model.OutboxEmail, error) {
+	query := `update email_outbox
+set next_attempt_at = now() + $3 * interval '1 millisecond'
+where id in (
+    select id
+    from email_outbox
+    where status = $1 and account_summary_id = $2 and next_attempt_at <= now()
+    for update skip locked)
+returning id, account_summary_id, attempts`
+
+	emails, err := repo.claim(ctx, query, model.OutboxPending, summaryID, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("repository: Repository: ClaimSummary %d: %w", summaryID, err)
+	}
+
+	return emails, nil
+}
+
+// claim runs a query that claims emails of the outbox, returning their id, summary id and attempts, and reads the
+// summary of each one.
+func (repo Repository) claim(ctx context.Context, query string, args ...interface{}) ([]model.OutboxEmail, error) {
+	if repo.DB == nil {
+		return nil, ErrNoDatabase
+	}
+
+	var claimed []claimedEmail
+	if err := repo.DB.SelectContext(ctx, &claimed, query, args...); err != nil {
+		return nil, err
+	}
+
+	emails := make([]model.OutboxEmail, 0, len(claimed))
+	for _, email := range claimed {
+		stored, err := repo.GetSummary(ctx, email.AccountSummaryID)
+		if err != nil {
+			return nil, err
+		}
+
+		emails = append(emails, model.OutboxEmail{ID: email.ID, Attempts: email.Attempts, Summary: stored.Summary})
+	}
+
+	return emails, nil
+}
+
+func (repo Repository) MarkSent(ctx context.Context, id int64) error {
+	query := `update email_outbox set status = $1, sent_at = now(), last_error = null where id = $2`
+
+	if err := repo.updateEmail(ctx, query, model.OutboxSent, id); err != nil {
+		return fmt.Errorf("repository: Repository: MarkSent %d: %w", id, err)
+	}
+
+	return nil
+}
+
+func (repo Repository) MarkRetry(
+	ctx context.Context, id int64, attempts int, lastErr string, nextAttemptAt time.Time,
+) error {
+	query := `update email_outbox set attempts = $1, last_error = $2, next_attempt_at = $3 where id = $4`
+
+	if err := repo.updateEmail(ctx, query, attempts, lastErr, nextAttemptAt, id); err != nil {
+		return fmt.Errorf("repository: Repository: MarkRetry %d: %w", id, err)
+	}
+
+	return nil
+}
+
+func (repo Repository) MarkFailed(ctx context.Context, id int64, attempts int, lastErr string) error {
+	query := `update email_outbox set status = $1, attempts = $2, last_error = $3 where id = $4`
+
+	if err := repo.updateEmail(ctx, query, model.OutboxFailed, attempts, lastErr, id); err != nil {
+		return fmt.Errorf("repository: Repository: MarkFailed %d: %w", id, err)
+	}
+
+	return nil
+}
+
+// updateEmail runs an update of a single email of the outbox, failing with ErrNotFound when it does not exist.
+func (repo Repository) updateEmail(ctx context.Context, query string, args ...interface{}) error {
+	if repo.DB == nil {
+		return ErrNoDatabase
+	}
+
+	result, err := repo.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if updated == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
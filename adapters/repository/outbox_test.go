@@ -0,0 +1,137 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stori/adapters/repository"
+	"stori/model"
+	"stori/test"
+)
+
+// The outbox tests are not parallel: claims would take the emails queued by the other tests.
+
+func TestClaim_WhenCreated_EmailClaimedOnceUntilLeaseExpires(t *testing.T) {
+	test.IntegrationTest(t)
+
+	// Arrange
+	sut := repository.New(sqlx.NewDb(DB, "postgres"))
+	email := "outbox.claim@example.com"
+	createExecution(t, sut, email, "outbox_claim.csv")
+
+	// Act
+	claimed, err := sut.Claim(context.Background(), 1000, time.Hour)
+	require.NoError(t, err)
+	claimedAgain, errAgain := sut.Claim(context.Background(), 1000, time.Hour)
+
+	// Assert
+	require.NoError(t, errAgain)
+	outboxEmail := findOutboxEmail(t, claimed, email)
+	assert.Equal(t, 0, outboxEmail.Attempts)
+	assert.Equal(t, 2, outboxEmail.Summary.TransactionsPerMonth[model.YearMonth{Year: 2024, Month: time.January}])
+	assert.Empty(t, claimedAgain)
+}
+
+func TestClaimSummary_OnlyEmailOfSummaryClaimed(t *testing.T) {
+	test.IntegrationTest(t)
+
+	// Arrange
+	sut := repository.New(sqlx.NewDb(DB, "postgres"))
+	summaryID := createExecution(t, sut, "outbox.claim.summary@example.com", "outbox_claim_summary.csv")
+	otherSummaryID := createExecution(t, sut, "outbox.claim.other@example.com", "outbox_claim_other.csv")
+
+	// Act
+	claimed, err := sut.ClaimSummary(context.Background(), summaryID, time.Hour)
+	require.NoError(t, err)
+	claimedAgain, errAgain := sut.ClaimSummary(context.Background(), summaryID, time.Hour)
+
+	// Assert
+	require.NoError(t, errAgain)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, "outbox.claim.summary@example.com", claimed[0].Summary.Email)
+	assert.Empty(t, claimedAgain)
+
+	claimedOther, err := sut.ClaimSummary(context.Background(), otherSummaryID, time.Hour)
+	require.NoError(t, err)
+	assert.Len(t, claimedOther, 1, "the email of the other summary is still pending")
+}
+
+func TestMark_UpdatesStatusOfEmail(t *testing.T) {
+	test.IntegrationTest(t)
+
+	// Arrange
+	dbx := sqlx.NewDb(DB, "postgres")
+	sut := repository.New(dbx)
+	createExecution(t, sut, "outbox.sent@example.com", "outbox_sent.csv")
+	createExecution(t, sut, "outbox.retry@example.com", "outbox_retry.csv")
+	createExecution(t, sut, "outbox.failed@example.com", "outbox_failed.csv")
+	claimed, err := sut.Claim(context.Background(), 1000, 0)
+	require.NoError(t, err)
+
+	sent := findOutboxEmail(t, claimed, "outbox.sent@example.com")
+	retried := findOutboxEmail(t, claimed, "outbox.retry@example.com")
+	failed := findOutboxEmail(t, claimed, "outbox.failed@example.com")
+
+	// Act
+	require.NoError(t, sut.MarkSent(context.Background(), sent.ID))
+	require.NoError(t, sut.MarkRetry(context.Background(), retried.ID, 1, "smtp unavailable", time.Now().Add(time.Hour)))
+	require.NoError(t, sut.MarkFailed(context.Background(), failed.ID, 5, "mailbox unavailable"))
+
+	// Assert
+	var statuses []struct {
+		ID        int64   `db:"id"`
+		Status    string  `db:"status"`
+		Attempts  int     `db:"attempts"`
+		LastError *string `db:"last_error"`
+		Sent      bool    `db:"sent"`
+	}
+	err = dbx.Select(&statuses, `select id, status, attempts, last_error, sent_at is not null as sent
+from email_outbox where id in ($1, $2, $3) order by id`, sent.ID, retried.ID, failed.ID)
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+
+	assert.Equal(t, model.OutboxSent, statuses[0].Status)
+	assert.True(t, statuses[0].Sent)
+	assert.Equal(t, model.OutboxPending, statuses[1].Status)
+	assert.Equal(t, 1, statuses[1].Attempts)
+	assert.Equal(t, "smtp unavailable", *statuses[1].LastError)
+	assert.Equal(t, model.OutboxFailed, statuses[2].Status)
+	assert.Equal(t, 5, statuses[2].Attempts)
+
+	claimedAgain, err := sut.Claim(context.Background(), 1000, 0)
+	require.NoError(t, err)
+	assert.Empty(t, claimedAgain, "sent, failed and postponed emails are not due")
+}
+
+func TestMarkSent_WhenDoesNotExist_Error(t *testing.T) {
+	test.IntegrationTest(t)
+
+	// Arrange
+	sut := repository.New(sqlx.NewDb(DB, "postgres"))
+
+	// Act
+	err := sut.MarkSent(context.Background(), -1)
+
+	// Assert
+	require.Error(t, err)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func findOutboxEmail(t *testing.T, emails []model.OutboxEmail, email string) model.OutboxEmail {
+	t.Helper()
+
+	for _, outboxEmail := range emails {
+		if outboxEmail.Summary.Email == email {
+			return outboxEmail
+		}
+	}
+
+	require.FailNow(t, "email not claimed", email)
+
+	return model.OutboxEmail{}
+}
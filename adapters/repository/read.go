@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"stori/accountsummary"
+	"stori/model"
+)
+
+const storedSummaryQuery = `select s.id, s.execution_id, s.email, s.account, s.total_balance, s.average_debit_amount,
+s.average_credit_amount, s.transactions_per_month, s.file_path, s.created_at, s.minimum_balance,
+s.minimum_balance_date, s.maximum_balance, s.maximum_balance_date, s.daily_balances, s.monthly_breakdown,
+e.id as "execution.id", e.source_uri as "execution.source_uri", e.checksum as "execution.checksum",
+e.status as "execution.status", e.started_at as "execution.started_at", e.finished_at as "execution.finished_at"
+from account_summary s
+join execution e on e.id = s.execution_id`
+
+func (repo Repository) GetSummary(ctx context.Context, id int64) (model.StoredSummary, error) {
+	fail := func(err error) (model.StoredSummary, error) {
+		return model.StoredSummary{}, fmt.Errorf("repository: Repository: GetSummary %d: %w", id, err)
+	}
+
+	if repo.DB == nil {
+		return fail(ErrNoDatabase)
+	}
+
+	var summary StoredSummary
+	err := repo.DB.GetContext(ctx, &summary, storedSummaryQuery+` where s.id = $1`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fail(ErrNotFound)
+	}
+
+	if err != nil {
+		return fail(err)
+	}
+
+	return repo.StoredSummaryToModel(summary), nil
+}
+
+// ListSummaries returns a page of the summaries sent to the email of the filter, newest first.
+func (repo Repository) ListSummaries(
+	ctx context.Context, filter accountsummary.SummaryFilter,
+) ([]model.StoredSummary, error) {
+	fail := func(err error) ([]model.StoredSummary, error) {
+		return nil, fmt.Errorf("repository: Repository: ListSummaries: %w", err)
+	}
+
+	if repo.DB == nil {
+		return fail(ErrNoDatabase)
+	}
+
+	conditions := []string{"s.email = :email"}
+	if !filter.CreatedFrom.IsZero() {
+		conditions = append(conditions, "s.created_at >= :created_from")
+	}
+
+	if !filter.CreatedTo.IsZero() {
+		conditions = append(conditions, "s.created_at <= :created_to")
+	}
+
+	query := storedSummaryQuery + ` where ` + strings.Join(conditions, " and ") +
+		` order by s.created_at desc, s.id desc limit :limit offset :offset`
+
+	rows, err := repo.DB.NamedQueryContext(ctx, query, map[string]interface{}{
+		"email":        filter.Email,
+		"created_from": filter.CreatedFrom,
+		"created_to":   filter.CreatedTo,
+		"limit":        filter.PageSize(),
+		"offset":       filter.Offset,
+	})
+	if err != nil {
+		return fail(err)
+	}
+
+	defer rows.Close()
+
+	summaries := make([]model.StoredSummary, 0)
+	for rows.Next() {
+		var summary StoredSummary
+		if err = rows.StructScan(&summary); err != nil {
+			return fail(err)
+		}
+
+		summaries = append(summaries, repo.StoredSummaryToModel(summary))
+	}
+
+	if err = rows.Err(); err != nil {
+		return fail(err)
+	}
+
+	return summaries, nil
+}
+
+// ListTransactions returns the transactions stored by an execution, in date order.
+func (repo Repository) ListTransactions(ctx context.Context, executionID string) ([]model.Transaction, error) {
+	fail := func(err error) ([]model.Transaction, error) {
+		return nil, fmt.Errorf("repository: Repository: ListTransactions %s: %w", executionID, err)
+	}
+
+	if repo.DB == nil {
+		return fail(ErrNoDatabase)
+	}
+
+	var found bool
+	query := `select exists(select 1 from execution where id = $1)`
+	if err := repo.DB.GetContext(ctx, &found, query, executionID); err != nil {
+		return fail(err)
+	}
+
+	if !found {
+		return fail(ErrNotFound)
+	}
+
+	query = `select id, execution_id, source_id, date, amount, file_path, account, attributes
+from transaction
+where execution_id = $1
+order by date, id`
+
+	var transactions []Transaction
+	if err := repo.DB.SelectContext(ctx, &transactions, query, executionID); err != nil {
+		return fail(err)
+	}
+
+	result := make([]model.Transaction, 0, len(transactions))
+	for _, transaction := range transactions {
+		result = append(result, repo.TransactionToModel(transaction))
+	}
+
+	return result, nil
+}
+
+// WasProcessed tells whether a completed execution summarized the source with the checksum for the email and
+// account. It is always false without a database, as nothing is stored.
+func (repo Repository) WasProcessed(ctx context.Context, email, account, checksum string) (bool, error) {
+	if repo.DB == nil {
+		return false, nil
+	}
+
+	query := `select exists(
+select 1
+from account_summary s
+join execution e on e.id = s.execution_id
+where s.email = $1 and coalesce(s.account, '') = $2 and e.checksum = $3 and e.status = $4)`
+
+	var processed bool
+	if err := repo.DB.GetContext(ctx, &processed, query, email, account, checksum, model.ExecutionCompleted); err != nil {
+		return false, fmt.Errorf("repository: Repository: WasProcessed: %w", err)
+	}
+
+	return processed, nil
+}
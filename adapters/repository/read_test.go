@@ -0,0 +1,257 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/govalues/decimal"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stori/accountsummary"
+	"stori/adapters/repository"
+	"stori/model"
+	"stori/test"
+	"stori/transactions"
+)
+
+func TestGetSummary_WhenCreated_Success(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := repository.New(sqlx.NewDb(DB, "postgres"))
+	email := "get.summary@example.com"
+	createExecution(t, sut, email, "get_summary.csv")
+	summaries, err := sut.ListSummaries(context.Background(), accountsummary.SummaryFilter{Email: email})
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+
+	// Act
+	stored, err := sut.GetSummary(context.Background(), summaries[0].ID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, summaries[0].Execution.ID, stored.Execution.ID)
+	assert.Equal(t, "get_summary.csv", stored.Execution.SourceURI)
+	assert.Equal(t, "sha256:get_summary.csv", stored.Execution.Checksum)
+	assert.Equal(t, model.ExecutionCompleted, stored.Execution.Status)
+	assert.False(t, stored.Execution.StartedAt.IsZero())
+	assert.False(t, stored.Execution.FinishedAt.IsZero())
+	assert.False(t, stored.CreatedAt.IsZero())
+	assert.Equal(t, email, stored.Summary.Email)
+	assert.Equal(t, 0, decimal.MustNew(200, 0).Cmp(stored.Summary.TotalBalance))
+	assert.Equal(t, 2, stored.Summary.TransactionsPerMonth[model.YearMonth{Year: 2024, Month: time.January}])
+}
+
+func TestGetSummary_WhenDoesNotExist_Error(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := repository.New(sqlx.NewDb(DB, "postgres"))
+
+	// Act
+	_, err := sut.GetSummary(context.Background(), -1)
+
+	// Assert
+	require.Error(t, err)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestListSummaries_WhenPaginated_NewestFirst(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := repository.New(sqlx.NewDb(DB, "postgres"))
+	email := "list.summaries@example.com"
+	createExecution(t, sut, email, "first.csv")
+	createExecution(t, sut, email, "second.csv")
+	createExecution(t, sut, email, "third.csv")
+
+	// Act
+	ctx := context.Background()
+	firstPage, errFirst := sut.ListSummaries(ctx, accountsummary.SummaryFilter{Email: email, Limit: 2})
+	secondPage, errSecond := sut.ListSummaries(ctx, accountsummary.SummaryFilter{Email: email, Limit: 2, Offset: 2})
+
+	// Assert
+	require.NoError(t, errFirst)
+	require.NoError(t, errSecond)
+	require.Len(t, firstPage, 2)
+	require.Len(t, secondPage, 1)
+	assert.Equal(t, "third.csv", firstPage[0].Execution.SourceURI)
+	assert.Equal(t, "second.csv", firstPage[1].Execution.SourceURI)
+	assert.Equal(t, "first.csv", secondPage[0].Execution.SourceURI)
+}
+
+func TestListSummaries_WhenDateFilters_OnlyWithinRange(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := repository.New(sqlx.NewDb(DB, "postgres"))
+	email := "filtered.summaries@example.com"
+	createExecution(t, sut, email, "filtered.csv")
+
+	// Act
+	inRange, errIn := sut.ListSummaries(context.Background(), accountsummary.SummaryFilter{
+		Email:       email,
+		CreatedFrom: time.Now().Add(-time.Hour),
+		CreatedTo:   time.Now().Add(time.Hour),
+	})
+	future, errFuture := sut.ListSummaries(context.Background(), accountsummary.SummaryFilter{
+		Email:       email,
+		CreatedFrom: time.Now().Add(time.Hour),
+	})
+
+	// Assert
+	require.NoError(t, errIn)
+	require.NoError(t, errFuture)
+	assert.Len(t, inRange, 1)
+	assert.Empty(t, future)
+}
+
+func TestListTransactions_WhenSameFileTwice_OnlyItsExecution(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := repository.New(sqlx.NewDb(DB, "postgres"))
+	email := "same.file@example.com"
+	createExecution(t, sut, email, "same_file.csv")
+	createExecution(t, sut, email, "same_file.csv")
+	summaries, err := sut.ListSummaries(context.Background(), accountsummary.SummaryFilter{Email: email})
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+
+	// Act
+	first, errFirst := sut.ListTransactions(context.Background(), summaries[0].Execution.ID)
+	second, errSecond := sut.ListTransactions(context.Background(), summaries[1].Execution.ID)
+
+	// Assert
+	require.NoError(t, errFirst)
+	require.NoError(t, errSecond)
+	assert.NotEqual(t, summaries[0].Execution.ID, summaries[1].Execution.ID)
+	assert.Len(t, first, 2)
+	assert.Len(t, second, 2)
+}
+
+func TestListTransactions_WhenExecutionDoesNotExist_Error(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := repository.New(sqlx.NewDb(DB, "postgres"))
+
+	// Act
+	_, err := sut.ListTransactions(context.Background(), "00000000-0000-4000-8000-000000000000")
+
+	// Assert
+	require.Error(t, err)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestListTransactions_WhenCreated_InDateOrder(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	// Arrange
+	sut := repository.New(sqlx.NewDb(DB, "postgres"))
+	email := "list.transactions@example.com"
+	createExecution(t, sut, email, "list_transactions.csv")
+	summaries, err := sut.ListSummaries(context.Background(), accountsummary.SummaryFilter{Email: email})
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+
+	// Act
+	stored, err := sut.ListTransactions(context.Background(), summaries[0].Execution.ID)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	assert.Equal(t, 2, stored[0].ID)
+	assert.Equal(t, 1, stored[1].ID)
+	assert.Equal(t, 0, decimal.MustNew(50, 0).Cmp(stored[0].Amount))
+	assert.Equal(t, 0, decimal.MustNew(150, 0).Cmp(stored[1].Amount))
+	assert.True(t, stored[0].Date.Before(stored[1].Date))
+}
+
+func TestWasProcessed(t *testing.T) {
+	test.IntegrationTest(t)
+	t.Parallel()
+
+	sut := repository.New(sqlx.NewDb(DB, "postgres"))
+	email := "was.processed@example.com"
+	createExecution(t, sut, email, "was_processed.csv")
+
+	testCases := []struct {
+		name     string
+		email    string
+		account  string
+		checksum string
+		expected bool
+	}{
+		{name: "same email and checksum", email: email, checksum: "sha256:was_processed.csv", expected: true},
+		{name: "other email", email: "other@example.com", checksum: "sha256:was_processed.csv"},
+		{name: "other account", email: email, account: "ACC-1", checksum: "sha256:was_processed.csv"},
+		{name: "other checksum", email: email, checksum: "sha256:other.csv"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			processed, err := sut.WasProcessed(context.Background(), tc.email, tc.account, tc.checksum)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, processed)
+		})
+	}
+}
+
+func TestWasProcessed_WhenNoDatabase_False(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	sut := repository.New(nil)
+
+	// Act
+	processed, err := sut.WasProcessed(context.Background(), "john.doe@stori.com", "", "sha256:abc")
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, processed)
+}
+
+// createExecution returns the id of the summary created.
+func createExecution(t *testing.T, repo *repository.Repository, email, filePath string) int64 {
+	t.Helper()
+
+	summaryID, err := repo.Create(context.Background(), accountsummary.Execution{
+		AccountSummary: model.AccountSummary{
+			Email:               email,
+			TotalBalance:        decimal.MustNew(200, 0),
+			AverageDebitAmount:  decimal.Zero,
+			AverageCreditAmount: decimal.MustNew(100, 0),
+			TransactionsPerMonth: map[model.YearMonth]int{
+				{Year: 2024, Month: time.January}: 2,
+			},
+		},
+		Transactions: transactions.Seq([]model.Transaction{
+			{ID: 1, Date: time.Date(2024, time.January, 20, 0, 0, 0, 0, time.UTC), Amount: decimal.MustNew(150, 0)},
+			{ID: 2, Date: time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC), Amount: decimal.MustNew(50, 0)},
+		}),
+		FilePath:   filePath,
+		Checksum:   "sha256:" + filePath,
+		StartedAt:  time.Now().UTC(),
+		QueueEmail: true,
+	})
+	require.NoError(t, err)
+
+	return summaryID
+}
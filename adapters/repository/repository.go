@@ -1,8 +1,12 @@
 package repository
 
 import (
+	"database/sql"
+
+	"github.com/govalues/decimal"
 	"github.com/jmoiron/sqlx"
 
+	"stori/accountsummary"
 	"stori/model"
 )
 
@@ -13,9 +17,22 @@ type (
 
 	Config struct {
 		DB *sqlx.DB
+		// BatchSize is the number of transactions inserted per statement. It defaults to DefaultBatchSize and is
+		// capped at MaxBatchSize.
+		BatchSize int
+		// CopyThreshold is the number of transactions from which they are loaded with COPY instead of batched
+		// inserts. It defaults to DefaultCopyThreshold, and a negative value never uses COPY.
+		CopyThreshold int
 	}
 )
 
+const (
+	DefaultBatchSize     = 1000
+	DefaultCopyThreshold = 10000
+	// MaxBatchSize keeps batched inserts within the 65535 bind parameters PostgreSQL accepts per statement.
+	MaxBatchSize = 65535 / 7
+)
+
 func New(db *sqlx.DB) *Repository {
 	return &Repository{
 		Config: Config{
@@ -24,21 +41,108 @@ func New(db *sqlx.DB) *Repository {
 	}
 }
 
-func (repo Repository) AccountSummaryFromModel(summary model.AccountSummary, filePath string) AccountSummary {
+func (config Config) batchSize() int {
+	if config.BatchSize <= 0 {
+		return DefaultBatchSize
+	}
+
+	return min(config.BatchSize, MaxBatchSize)
+}
+
+// copyThreshold is 0 when COPY is disabled.
+func (config Config) copyThreshold() int {
+	switch {
+	case config.CopyThreshold < 0:
+		return 0
+	case config.CopyThreshold == 0:
+		return DefaultCopyThreshold
+	default:
+		return config.CopyThreshold
+	}
+}
+
+func (repo Repository) ExecutionFromModel(execution accountsummary.Execution, id string) Execution {
+	return Execution{
+		ID:        id,
+		SourceURI: execution.FilePath,
+		Checksum:  sql.NullString{String: execution.Checksum, Valid: execution.Checksum != ""},
+		Status:    model.ExecutionRunning,
+		StartedAt: execution.StartedAt,
+	}
+}
+
+func (repo Repository) AccountSummaryFromModel(
+	summary model.AccountSummary, filePath, executionID string,
+) AccountSummary {
 	return AccountSummary{
+		ExecutionID:          executionID,
 		Email:                summary.Email,
+		Account:              sql.NullString{String: summary.Account, Valid: summary.Account != ""},
 		TotalBalance:         summary.TotalBalance,
 		AverageDebitAmount:   summary.AverageDebitAmount,
 		AverageCreditAmount:  summary.AverageCreditAmount,
 		TransactionsPerMonth: summary.TransactionsPerMonth,
 		FilePath:             filePath,
+		MinimumBalance:       decimal.NullDecimal{Decimal: summary.MinimumBalance, Valid: true},
+		MinimumBalanceDate:   sql.NullTime{Time: summary.MinimumBalanceDate, Valid: true},
+		MaximumBalance:       decimal.NullDecimal{Decimal: summary.MaximumBalance, Valid: true},
+		MaximumBalanceDate:   sql.NullTime{Time: summary.MaximumBalanceDate, Valid: true},
+		DailyBalances:        summary.DailyBalances,
+		MonthlyBreakdown:     summary.MonthlyBreakdown,
 	}
 }
 
-func (repo Repository) TransactionFromModel(transaction model.Transaction, filePath string) Transaction {
+func (repo Repository) StoredSummaryToModel(stored StoredSummary) model.StoredSummary {
+	summary, execution := stored.AccountSummary, stored.Execution
+
+	return model.StoredSummary{
+		ID:        summary.ID,
+		CreatedAt: summary.CreatedAt,
+		Execution: model.ExecutionRecord{
+			ID:         execution.ID,
+			SourceURI:  execution.SourceURI,
+			Checksum:   execution.Checksum.String,
+			Status:     execution.Status,
+			StartedAt:  execution.StartedAt,
+			FinishedAt: execution.FinishedAt.Time,
+		},
+		Summary: model.AccountSummary{
+			Email:                summary.Email,
+			Account:              summary.Account.String,
+			TotalBalance:         summary.TotalBalance,
+			AverageDebitAmount:   summary.AverageDebitAmount,
+			AverageCreditAmount:  summary.AverageCreditAmount,
+			TransactionsPerMonth: summary.TransactionsPerMonth,
+			MinimumBalance:       summary.MinimumBalance.Decimal,
+			MinimumBalanceDate:   summary.MinimumBalanceDate.Time,
+			MaximumBalance:       summary.MaximumBalance.Decimal,
+			MaximumBalanceDate:   summary.MaximumBalanceDate.Time,
+			DailyBalances:        summary.DailyBalances,
+			MonthlyBreakdown:     summary.MonthlyBreakdown,
+		},
+	}
+}
+
+func (repo Repository) TransactionFromModel(transaction model.Transaction, filePath, executionID string) Transaction {
 	return Transaction{
-		Date:     transaction.Date,
-		Amount:   transaction.Amount,
-		FilePath: filePath,
+		ExecutionID: executionID,
+		SourceID:    sql.NullInt64{Int64: int64(transaction.ID), Valid: true},
+		Date:        transaction.Date,
+		Amount:      transaction.Amount,
+		FilePath:    filePath,
+		Account:     sql.NullString{String: transaction.Account, Valid: transaction.Account != ""},
+		Attributes:  transaction.Attributes,
+	}
+}
+
+// TransactionToModel identifies the transaction by the id it had in its source, which is 0 for the transactions
+// stored before it was kept.
+func (repo Repository) TransactionToModel(transaction Transaction) model.Transaction {
+	return model.Transaction{
+		ID:         int(transaction.SourceID.Int64),
+		Date:       transaction.Date,
+		Amount:     transaction.Amount,
+		Account:    transaction.Account.String,
+		Attributes: transaction.Attributes,
 	}
 }
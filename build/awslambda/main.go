@@ -5,16 +5,20 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
+	"net/http"
 	"os"
 	"strconv"
-	"strings"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sqs"
 
 	"stori/accountsummary"
 	"stori/adapters/emailsender"
 	"stori/adapters/filereader"
+	"stori/adapters/recipients"
 	"stori/adapters/repository"
 )
 
@@ -25,60 +29,206 @@ const (
 	EmailPort     = "EMAIL_PORT"
 	EmailUsername = "EMAIL_USERNAME"
 	EmailPassword = "EMAIL_PASSWORD"
+	EmailLocale   = "EMAIL_LOCALE"
+	S3Endpoint    = "S3_ENDPOINT"
+	S3PathStyle   = "S3_PATH_STYLE"
+
+	S3MaxObjectSize = "S3_MAX_OBJECT_SIZE"
+
+	HTTPBearerToken = "HTTP_BEARER_TOKEN"
+	HTTPUsername    = "HTTP_USERNAME"
+	HTTPPassword    = "HTTP_PASSWORD"
+
+	SQSPoisonQueueURL = "SQS_POISON_QUEUE_URL"
+	SQSMaxAttempts    = "SQS_MAX_ATTEMPTS"
 
 	filePathKey = "filepath"
 	emailKey    = "email"
 )
 
-func handleRequest(_ context.Context, event *events.APIGatewayV2HTTPRequest) (*string, error) {
-	body := map[string]string{}
+var (
+	errInvalidBody     = errors.New("body is not a JSON object of strings")
+	errMissingFilePath = errors.New("filepath not found")
+	errMissingEmail    = errors.New("email not found")
+)
 
-	err := json.Unmarshal([]byte(event.Body), &body)
-	if err != nil {
-		panic(err)
+type (
+	// recipientResolver finds who the summary of an object dropped in a bucket is sent to.
+	recipientResolver interface {
+		Email(ctx context.Context, bucket, key string) (string, error)
 	}
 
-	filePath, ok := body[filePathKey]
-	if !ok {
-		panic(errors.New("filepath not found"))
+	// handler builds the dependencies of the app for every request, so that tests can replace them.
+	handler struct {
+		buildReader      func(filePath string) (accountsummary.TransactionsReader, error)
+		buildEmailSender func() (accountsummary.EmailSender, error)
+		repository       accountsummary.Repository
+		recipients       recipientResolver
+		poison           poisonRouter
+		sqsMaxAttempts   int
 	}
+)
 
-	email, ok := body[emailKey]
-	if !ok {
-		panic(errors.New("email not found"))
+func newHandler() (handler, error) {
+	// The region and the credentials are the ones of the function, which needs to read the objects, their metadata
+	// and their tags.
+	readerConfig := filereader.Config{
+		S3: filereader.S3Config{
+			Endpoint:  os.Getenv(S3Endpoint),
+			PathStyle: os.Getenv(S3PathStyle) == "true",
+		},
+		HTTP: filereader.HTTPConfig{
+			BearerToken: os.Getenv(HTTPBearerToken),
+			Username:    os.Getenv(HTTPUsername),
+			Password:    os.Getenv(HTTPPassword),
+		},
 	}
 
-	emailSender, err := buildEmailSender()
+	if maxObjectSize := os.Getenv(S3MaxObjectSize); maxObjectSize != "" {
+		size, errSize := strconv.ParseInt(maxObjectSize, 10, 64)
+		if errSize != nil {
+			return handler{}, fmt.Errorf("awslambda: newHandler: %s: %w", S3MaxObjectSize, errSize)
+		}
+
+		readerConfig.S3.MaxObjectSize = size
+	}
+
+	client, err := filereader.NewS3Client(readerConfig.S3)
 	if err != nil {
-		panic(err)
+		return handler{}, fmt.Errorf("awslambda: newHandler: %w", err)
 	}
 
-	reader := buildTransactionsReader(filePath)
-	noopRepo := repository.New(nil)
+	h := handler{
+		buildReader: func(filePath string) (accountsummary.TransactionsReader, error) {
+			return filereader.NewReader(filePath, readerConfig)
+		},
+		buildEmailSender: buildEmailSender,
+		repository:       repository.New(nil),
+		recipients:       recipients.NewS3Objects(client),
+	}
 
-	application := accountsummary.New(accountsummary.Config{
-		Email:              email,
-		FilePath:           filePath,
-		TransactionsReader: reader,
-		EmailSender:        emailSender,
-		Repository:         noopRepo,
-	})
+	if queueURL := os.Getenv(SQSPoisonQueueURL); queueURL != "" {
+		sess, errSession := session.NewSession()
+		if errSession != nil {
+			return handler{}, fmt.Errorf("awslambda: newHandler: %w", errSession)
+		}
+
+		h.poison = sqsPoisonQueue{client: sqs.New(sess), queueURL: queueURL}
+	}
+
+	if maxAttempts := os.Getenv(SQSMaxAttempts); maxAttempts != "" {
+		if h.sqsMaxAttempts, err = strconv.Atoi(maxAttempts); err != nil {
+			return handler{}, fmt.Errorf("awslambda: newHandler: %s: %w", SQSMaxAttempts, err)
+		}
+	}
+
+	return h, nil
+}
+
+// invoke routes the payload of the invocation by the source of its records: S3 notifications and SQS batches are
+// handled as such, and anything else as an HTTP request of the function URL or API Gateway.
+func (h handler) invoke(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	switch eventSourceOf(payload) {
+	case s3EventSource:
+		var event events.S3Event
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, fmt.Errorf("awslambda: invoke: %w", err)
+		}
+
+		return h.handleS3Event(ctx, event), nil
+	case sqsEventSource:
+		var event events.SQSEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, fmt.Errorf("awslambda: invoke: %w", err)
+		}
+
+		return h.handleSQSEvent(ctx, event), nil
+	}
+
+	var request events.APIGatewayV2HTTPRequest
+	if err := json.Unmarshal(payload, &request); err != nil {
+		return nil, fmt.Errorf("awslambda: invoke: %w", err)
+	}
+
+	return h.handleRequest(ctx, &request)
+}
+
+// handleRequest runs the app within the deadline of the invocation, which cancels the work in flight when reached.
+// Errors are answered with their status code and a JSON body, rather than failing the invocation.
+func (h handler) handleRequest(
+	ctx context.Context, event *events.APIGatewayV2HTTPRequest,
+) (events.APIGatewayV2HTTPResponse, error) {
+	requestID := requestIDOf(ctx, event)
 
-	if errRun := application.Run(); errRun != nil {
-		panic(errRun)
+	email, filePath, err := parseJob(event.Body)
+	if err != nil {
+		return errorResponse(requestID, err), nil
+	}
+
+	if err = h.process(ctx, email, filePath); err != nil {
+		return errorResponse(requestID, err), nil
 	}
 
 	successMessage := fmt.Sprintf("Process executed successfully for email: %s and file: %s", email, filePath)
 
-	return &successMessage, nil
+	return jsonResponse(http.StatusOK, responseBody{RequestID: requestID, Message: successMessage}), nil
+}
+
+// parseJob reads the email and the file path of a JSON body like {"filepath": "s3://bucket/key", "email": "..."}.
+func parseJob(body string) (string, string, error) {
+	job := map[string]string{}
+	if err := json.Unmarshal([]byte(body), &job); err != nil {
+		return "", "", fmt.Errorf("%w: %w", errInvalidBody, err)
+	}
+
+	filePath, ok := job[filePathKey]
+	if !ok {
+		return "", "", errMissingFilePath
+	}
+
+	email, ok := job[emailKey]
+	if !ok {
+		return "", "", errMissingEmail
+	}
+
+	return email, filePath, nil
+}
+
+// eventSourceOf is the source of the records of the payload, empty when it has none.
+func eventSourceOf(payload json.RawMessage) string {
+	var event struct {
+		Records []struct {
+			EventSource string `json:"eventSource"`
+		} `json:"Records"`
+	}
+	if err := json.Unmarshal(payload, &event); err != nil || len(event.Records) == 0 {
+		return ""
+	}
+
+	return event.Records[0].EventSource
 }
 
-func buildTransactionsReader(filepath string) accountsummary.TransactionsReader {
-	if strings.HasPrefix(filepath, s3FilePathPrefix) {
-		return filereader.NewS3Reader(filepath)
+// process sends the summary of the file to the email, whichever event asked for it.
+func (h handler) process(ctx context.Context, email, filePath string) error {
+	emailSender, err := h.buildEmailSender()
+	if err != nil {
+		return err
+	}
+
+	reader, err := h.buildReader(filePath)
+	if err != nil {
+		return err
 	}
 
-	return filereader.NewLocalReader(filepath)
+	application := accountsummary.New(accountsummary.Config{
+		Email:              email,
+		FilePath:           filereader.RedactURI(filePath),
+		TransactionsReader: reader,
+		EmailSender:        emailSender,
+		Repository:         h.repository,
+	})
+
+	return application.Run(ctx)
 }
 
 func buildEmailSender() (accountsummary.EmailSender, error) {
@@ -90,15 +240,24 @@ func buildEmailSender() (accountsummary.EmailSender, error) {
 
 	username := os.Getenv(EmailUsername)
 	password := os.Getenv(EmailPassword)
+	locale := os.Getenv(EmailLocale)
 
 	return emailsender.New(emailsender.Config{
 		Host:     host,
 		Port:     port,
 		Username: username,
 		Password: password,
+		Locale:   locale,
 	}), nil
 }
 
 func main() {
-	lambda.Start(handleRequest)
+	log.SetFlags(0)
+
+	h, err := newHandler()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	lambda.Start(h.invoke)
 }
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"stori/accountsummary"
+	"stori/adapters/emailsender"
+	"stori/adapters/filereader"
+	mocks "stori/mocks/stori/accountsummary"
+	"stori/model"
+	trans "stori/transactions"
+)
+
+const requestID = "request-1"
+
+func TestHandleRequest(t *testing.T) {
+	t.Parallel()
+
+	validBody := `{"filepath": "s3://bucket/transactions.csv", "email": "john.doe@stori.com"}`
+	transactions := []model.Transaction{
+		{ID: 1, Date: time.Date(2024, time.July, 15, 0, 0, 0, 0, time.UTC), Amount: decimal.MustParse("60.5")},
+	}
+
+	testCases := []struct {
+		name           string
+		body           string
+		readerErr      error
+		noTransactions bool
+		sendErr        error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "success", body: validBody, expectedStatus: http.StatusOK},
+		{name: "malformed JSON", body: `{"filepath":`, expectedStatus: http.StatusBadRequest, expectedCode: "invalid_body"},
+		{
+			name: "missing filepath", body: `{"email": "john.doe@stori.com"}`,
+			expectedStatus: http.StatusBadRequest, expectedCode: "missing_filepath",
+		},
+		{
+			name: "missing email", body: `{"filepath": "s3://bucket/transactions.csv"}`,
+			expectedStatus: http.StatusBadRequest, expectedCode: "missing_email",
+		},
+		{
+			name: "invalid email", body: `{"filepath": "s3://bucket/transactions.csv", "email": "john.doe"}`,
+			expectedStatus: http.StatusBadRequest, expectedCode: "invalid_email",
+		},
+		{
+			name: "invalid URI", body: validBody, readerErr: filereader.ErrInvalidURI,
+			expectedStatus: http.StatusBadRequest, expectedCode: "invalid_uri",
+		},
+		{
+			name: "invalid amount", body: validBody,
+			readerErr:      &filereader.RowError{Line: 2, Column: "transaction", Err: filereader.ErrInvalidAmount},
+			expectedStatus: http.StatusUnprocessableEntity, expectedCode: "invalid_amount",
+		},
+		{
+			name: "invalid file", body: validBody, readerErr: filereader.ErrInvalidFile,
+			expectedStatus: http.StatusUnprocessableEntity, expectedCode: "invalid_file",
+		},
+		{
+			name: "no transactions", body: validBody, noTransactions: true,
+			expectedStatus: http.StatusUnprocessableEntity, expectedCode: "no_transactions",
+		},
+		{
+			name: "S3 download failure", body: validBody, readerErr: filereader.ErrDownloadFile,
+			expectedStatus: http.StatusBadGateway, expectedCode: "storage_unavailable",
+		},
+		{
+			name: "S3 failure while parsing", body: validBody,
+			readerErr:      fmt.Errorf("%w: %w", filereader.ErrInvalidFile, filereader.ErrDownloadFile),
+			expectedStatus: http.StatusBadGateway, expectedCode: "storage_unavailable",
+		},
+		{
+			name: "object too large", body: validBody, readerErr: filereader.ErrObjectTooLarge,
+			expectedStatus: http.StatusUnprocessableEntity, expectedCode: "file_too_large",
+		},
+		{
+			name: "decompression bomb", body: validBody,
+			readerErr:      fmt.Errorf("%w: %w", filereader.ErrInvalidFile, filereader.ErrArchiveTooLarge),
+			expectedStatus: http.StatusUnprocessableEntity, expectedCode: "archive_too_large",
+		},
+		{
+			name: "corrupted archive", body: validBody, readerErr: filereader.ErrDecompression,
+			expectedStatus: http.StatusUnprocessableEntity, expectedCode: "invalid_archive",
+		},
+		{
+			name: "SMTP failure", body: validBody, sendErr: emailsender.ErrSendEmail,
+			expectedStatus: http.StatusBadGateway, expectedCode: "email_unavailable",
+		},
+		{
+			name: "deadline exceeded", body: validBody, readerErr: context.DeadlineExceeded,
+			expectedStatus: http.StatusGatewayTimeout, expectedCode: "timeout",
+		},
+		{
+			name: "unexpected error", body: validBody, readerErr: errors.New("unexpected"),
+			expectedStatus: http.StatusInternalServerError, expectedCode: "internal_error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			readerStub := mocks.NewMockTransactionsReader(t)
+			emailSenderStub := mocks.NewMockEmailSender(t)
+			repositoryStub := mocks.NewMockRepository(t)
+
+			stream := trans.Seq(transactions)
+			switch {
+			case tc.readerErr != nil:
+				stream = func(yield func(model.Transaction, error) bool) {
+					yield(model.Transaction{}, fmt.Errorf("filereader: S3: StreamTransactions: %w", tc.readerErr))
+				}
+			case tc.noTransactions:
+				stream = trans.Seq([]model.Transaction{})
+			}
+
+			readerStub.EXPECT().StreamTransactions(mock.Anything).Return(stream).Maybe()
+			repositoryStub.EXPECT().Create(mock.Anything, mock.Anything).Return(int64(1), nil).Maybe()
+			emailSenderStub.EXPECT().Send(mock.Anything, mock.Anything).Return(tc.sendErr).Maybe()
+
+			sut := handler{
+				buildReader: func(string) (accountsummary.TransactionsReader, error) {
+					return readerStub, nil
+				},
+				buildEmailSender: func() (accountsummary.EmailSender, error) { return emailSenderStub, nil },
+				repository:       repositoryStub,
+			}
+			event := &events.APIGatewayV2HTTPRequest{Body: tc.body}
+			event.RequestContext.RequestID = requestID
+
+			// Act
+			response, err := sut.handleRequest(context.Background(), event)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, response.StatusCode)
+			assert.Equal(t, "application/json", response.Headers["Content-Type"])
+
+			var body responseBody
+			require.NoError(t, json.Unmarshal([]byte(response.Body), &body))
+			assert.Equal(t, requestID, body.RequestID)
+			assert.Equal(t, tc.expectedCode, body.Code)
+			assert.NotEmpty(t, body.Message)
+		})
+	}
+}
+
+func TestHandleRequest_WhenServerError_DetailsNotExposed(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	sut := handler{
+		buildEmailSender: func() (accountsummary.EmailSender, error) {
+			return nil, errors.New("EMAIL_PASSWORD=secret is invalid")
+		},
+	}
+	event := &events.APIGatewayV2HTTPRequest{Body: `{"filepath": "s3://bucket/a.csv", "email": "john.doe@stori.com"}`}
+
+	// Act
+	response, err := sut.handleRequest(context.Background(), event)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, response.StatusCode)
+	assert.NotContains(t, response.Body, "secret")
+}
+
+func TestHandleRequest_WhenPresignedURL_SignatureNotStored(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	readerStub := mocks.NewMockTransactionsReader(t)
+	emailSenderStub := mocks.NewMockEmailSender(t)
+	repositoryMock := mocks.NewMockRepository(t)
+
+	transactions := []model.Transaction{
+		{ID: 1, Date: time.Date(2024, time.July, 15, 0, 0, 0, 0, time.UTC), Amount: decimal.MustParse("60.5")},
+	}
+	readerStub.EXPECT().StreamTransactions(mock.Anything).Return(trans.Seq(transactions))
+	emailSenderStub.EXPECT().Send(mock.Anything, mock.Anything).Return(nil)
+	repositoryMock.EXPECT().Create(mock.Anything, mock.MatchedBy(func(execution accountsummary.Execution) bool {
+		return execution.FilePath == "https://bucket.s3.amazonaws.com/transactions.csv"
+	})).Return(int64(1), nil)
+
+	sut := handler{
+		buildReader: func(string) (accountsummary.TransactionsReader, error) {
+			return readerStub, nil
+		},
+		buildEmailSender: func() (accountsummary.EmailSender, error) { return emailSenderStub, nil },
+		repository:       repositoryMock,
+	}
+	event := &events.APIGatewayV2HTTPRequest{Body: `{"filepath": ` +
+		`"https://bucket.s3.amazonaws.com/transactions.csv?X-Amz-Signature=secret", "email": "john.doe@stori.com"}`}
+
+	// Act
+	response, err := sut.handleRequest(context.Background(), event)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+
+	"stori/accountsummary"
+	"stori/adapters/emailsender"
+	"stori/adapters/filereader"
+	"stori/adapters/recipients"
+)
+
+type (
+	// responseBody is the JSON body of every response. Code is only set on errors.
+	responseBody struct {
+		RequestID string `json:"requestId"`
+		Code      string `json:"code,omitempty"`
+		Message   string `json:"message"`
+	}
+
+	// errorKind is how an error is answered: its status code and the code clients can rely on.
+	errorKind struct {
+		err    error
+		status int
+		code   string
+	}
+)
+
+// errorKinds are checked in order, so that the most specific errors come first. Any other error is internal.
+var errorKinds = []errorKind{ //nolint:gochecknoglobals // Read only
+	{err: errInvalidBody, status: http.StatusBadRequest, code: "invalid_body"},
+	{err: errMissingFilePath, status: http.StatusBadRequest, code: "missing_filepath"},
+	{err: errMissingEmail, status: http.StatusBadRequest, code: "missing_email"},
+	{err: accountsummary.ErrInvalidEmail, status: http.StatusBadRequest, code: "invalid_email"},
+	{err: emailsender.ErrWrongTargetAddress, status: http.StatusBadRequest, code: "invalid_email"},
+	{err: filereader.ErrInvalidURI, status: http.StatusBadRequest, code: "invalid_uri"},
+	{err: filereader.ErrUnsupportedScheme, status: http.StatusBadRequest, code: "invalid_uri"},
+	{err: recipients.ErrNoRecipient, status: http.StatusUnprocessableEntity, code: "missing_recipient"},
+	{err: accountsummary.ErrAlreadyProcessed, status: http.StatusConflict, code: "already_processed"},
+	// Objects are parsed while they are read, so that failing to read them also fails their parsing: these come first.
+	{err: context.DeadlineExceeded, status: http.StatusGatewayTimeout, code: "timeout"},
+	{err: filereader.ErrS3Connection, status: http.StatusBadGateway, code: "storage_unavailable"},
+	{err: filereader.ErrDownloadFile, status: http.StatusBadGateway, code: "storage_unavailable"},
+	{err: recipients.ErrObjectLookup, status: http.StatusBadGateway, code: "storage_unavailable"},
+	{err: emailsender.ErrSendEmail, status: http.StatusBadGateway, code: "email_unavailable"},
+	{err: filereader.ErrObjectTooLarge, status: http.StatusUnprocessableEntity, code: "file_too_large"},
+	{err: filereader.ErrArchiveTooLarge, status: http.StatusUnprocessableEntity, code: "archive_too_large"},
+	{err: filereader.ErrDecompression, status: http.StatusUnprocessableEntity, code: "invalid_archive"},
+	{err: filereader.ErrUnsupportedContentType, status: http.StatusUnprocessableEntity, code: "unsupported_type"},
+	{err: filereader.ErrFileNotFound, status: http.StatusUnprocessableEntity, code: "file_not_found"},
+	{err: filereader.ErrFileIsEmpty, status: http.StatusUnprocessableEntity, code: "empty_file"},
+	{err: filereader.ErrInvalidHeader, status: http.StatusUnprocessableEntity, code: "invalid_header"},
+	{err: filereader.ErrInvalidID, status: http.StatusUnprocessableEntity, code: "invalid_id"},
+	{err: filereader.ErrInvalidDateFormat, status: http.StatusUnprocessableEntity, code: "invalid_date"},
+	{err: filereader.ErrInvalidAmount, status: http.StatusUnprocessableEntity, code: "invalid_amount"},
+	{err: filereader.ErrInvalidAccount, status: http.StatusUnprocessableEntity, code: "invalid_account"},
+	{err: filereader.ErrInvalidFile, status: http.StatusUnprocessableEntity, code: "invalid_file"},
+	{err: accountsummary.ErrNoTransactions, status: http.StatusUnprocessableEntity, code: "no_transactions"},
+}
+
+// internalError is the kind of any error missing from errorKinds.
+var internalError = errorKind{ //nolint:gochecknoglobals // Read only
+	status: http.StatusInternalServerError,
+	code:   "internal_error",
+}
+
+func kindOf(err error) errorKind {
+	for _, kind := range errorKinds {
+		if errors.Is(err, kind.err) {
+			return kind
+		}
+	}
+
+	return internalError
+}
+
+// errorResponse answers with the kind of the error. Client errors explain what is wrong with the request, while
+// the details of the others are only logged, along with the request id to find them.
+func errorResponse(requestID string, err error) events.APIGatewayV2HTTPResponse {
+	kind := kindOf(err)
+	message := err.Error()
+	if kind.status >= http.StatusInternalServerError {
+		log.Printf("request %s failed: %v", requestID, err)
+		message = http.StatusText(kind.status)
+	}
+
+	return jsonResponse(kind.status, responseBody{RequestID: requestID, Code: kind.code, Message: message})
+}
+
+func jsonResponse(status int, body responseBody) events.APIGatewayV2HTTPResponse {
+	content, err := json.Marshal(body)
+	if err != nil {
+		status, content = http.StatusInternalServerError, []byte(`{"code":"internal_error"}`)
+	}
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: status,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       string(content),
+	}
+}
+
+// requestIDOf is the id API Gateway gave to the request, or else the id of the invocation.
+func requestIDOf(ctx context.Context, event *events.APIGatewayV2HTTPRequest) string {
+	if event.RequestContext.RequestID != "" {
+		return event.RequestContext.RequestID
+	}
+
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		return lc.AwsRequestID
+	}
+
+	return ""
+}
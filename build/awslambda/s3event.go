@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// s3EventSource is the source of the records S3 notifies.
+const s3EventSource = "aws:s3"
+
+type (
+	// recordResult is the outcome of a record of an S3 event. Code is only set on errors.
+	recordResult struct {
+		Bucket  string `json:"bucket"`
+		Key     string `json:"key"`
+		Email   string `json:"email,omitempty"`
+		Status  int    `json:"status"`
+		Code    string `json:"code,omitempty"`
+		Message string `json:"message"`
+	}
+
+	// s3EventResponse reports every record of an S3 event, in the order of the event.
+	s3EventResponse struct {
+		Records []recordResult `json:"records"`
+		Failed  int            `json:"failed"`
+	}
+)
+
+// handleS3Event sends the summary of every object of the event to the recipient found for it. A failed record
+// does not stop the others, and is reported and logged rather than failing the invocation: S3 invokes the function
+// asynchronously, so a failed invocation would be retried and the summaries already sent would be sent again.
+func (h handler) handleS3Event(ctx context.Context, event events.S3Event) s3EventResponse {
+	response := s3EventResponse{Records: make([]recordResult, 0, len(event.Records))}
+
+	for _, record := range event.Records {
+		result := h.handleS3Record(ctx, record.S3.Bucket.Name, record.S3.Object.URLDecodedKey)
+		if result.Status != http.StatusOK {
+			response.Failed++
+		}
+
+		response.Records = append(response.Records, result)
+	}
+
+	return response
+}
+
+func (h handler) handleS3Record(ctx context.Context, bucket, key string) recordResult {
+	result := recordResult{Bucket: bucket, Key: key}
+	filePath := s3FilePathPrefix + bucket + "/" + key
+
+	fail := func(err error) recordResult {
+		kind := kindOf(err)
+		log.Printf("record %s failed: %v", filePath, err)
+		result.Status, result.Code, result.Message = kind.status, kind.code, err.Error()
+
+		return result
+	}
+
+	email, err := h.recipients.Email(ctx, bucket, key)
+	if err != nil {
+		return fail(err)
+	}
+
+	result.Email = email
+	if err = h.process(ctx, email, filePath); err != nil {
+		return fail(err)
+	}
+
+	result.Status, result.Message = http.StatusOK, "Summary sent"
+
+	return result
+}
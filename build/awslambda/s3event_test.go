@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"stori/accountsummary"
+	"stori/adapters/filereader"
+	"stori/adapters/recipients"
+	mocks "stori/mocks/stori/accountsummary"
+	"stori/model"
+	"stori/test"
+	trans "stori/transactions"
+)
+
+const bucket = "statements"
+
+// s3Event is a notification of objects created in the bucket, with their keys URL encoded as S3 does.
+func s3Event(t *testing.T, keys ...string) json.RawMessage {
+	t.Helper()
+
+	records := make([]string, 0, len(keys))
+	for _, key := range keys {
+		records = append(records, fmt.Sprintf(`{
+			"eventSource": "aws:s3",
+			"eventName": "ObjectCreated:Put",
+			"s3": {"bucket": {"name": %q}, "object": {"key": %q, "size": 42}}
+		}`, bucket, key))
+	}
+
+	return json.RawMessage(`{"Records": [` + strings.Join(records, ",") + `]}`)
+}
+
+// s3Handler answers with the objects of a local S3, reading the transactions of every file but invalid.csv.
+func s3Handler(t *testing.T, fake *test.FakeS3, sent *[]string) handler {
+	t.Helper()
+
+	transactions := []model.Transaction{
+		{ID: 1, Date: time.Date(2024, time.July, 15, 0, 0, 0, 0, time.UTC), Amount: decimal.MustParse("60.5")},
+	}
+
+	emailSenderStub := mocks.NewMockEmailSender(t)
+	emailSenderStub.EXPECT().Send(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, summary model.AccountSummary) error {
+			*sent = append(*sent, summary.Email)
+			return nil
+		}).Maybe()
+
+	repositoryStub := mocks.NewMockRepository(t)
+	repositoryStub.EXPECT().Create(mock.Anything, mock.Anything).Return(int64(1), nil).Maybe()
+	repositoryStub.EXPECT().WasProcessed(mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(false, nil).Maybe()
+
+	return handler{
+		buildReader: func(filePath string) (accountsummary.TransactionsReader, error) {
+			readerStub := mocks.NewMockTransactionsReader(t)
+			stream := trans.Seq(transactions)
+			if strings.HasSuffix(filePath, "invalid.csv") {
+				stream = func(yield func(model.Transaction, error) bool) {
+					yield(model.Transaction{}, fmt.Errorf("filereader: S3: StreamTransactions: %w", filereader.ErrInvalidFile))
+				}
+			}
+
+			readerStub.EXPECT().StreamTransactions(mock.Anything).Return(stream).Maybe()
+
+			return readerStub, nil
+		},
+		buildEmailSender: func() (accountsummary.EmailSender, error) { return emailSenderStub, nil },
+		repository:       repositoryStub,
+		recipients:       recipients.NewS3Objects(fake.Client()),
+	}
+}
+
+func TestInvoke_WhenS3Event_ReportsEveryRecord(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	fake := test.NewFakeS3(t)
+	fake.Put(bucket, "daily/july.csv", test.FakeObject{Metadata: map[string]string{"email": "metadata@stori.com"}})
+	fake.Put(bucket, "daily/tagged.csv", test.FakeObject{Tags: map[string]string{"email": "tag@stori.com"}})
+	fake.Put(bucket, "daily/john.doe@stori.com/july 2024.csv", test.FakeObject{})
+	fake.Put(bucket, "daily/nobody.csv", test.FakeObject{})
+	fake.Put(bucket, "daily/jane.doe@stori.com/invalid.csv", test.FakeObject{})
+
+	var sent []string
+	sut := s3Handler(t, fake, &sent)
+	event := s3Event(t,
+		"daily/july.csv",
+		"daily/tagged.csv",
+		"daily/john.doe%40stori.com/july+2024.csv",
+		"daily/nobody.csv",
+		"daily/missing.csv",
+		"daily/jane.doe%40stori.com/invalid.csv",
+	)
+
+	// Act
+	response, err := sut.invoke(context.Background(), event)
+
+	// Assert
+	require.NoError(t, err)
+	require.IsType(t, s3EventResponse{}, response)
+
+	report := response.(s3EventResponse) //nolint:forcetypeassert // Checked above
+	assert.Equal(t, 3, report.Failed)
+	assert.Equal(t, []recordResult{
+		{
+			Bucket: bucket, Key: "daily/july.csv", Email: "metadata@stori.com",
+			Status: http.StatusOK, Message: "Summary sent",
+		},
+		{
+			Bucket: bucket, Key: "daily/tagged.csv", Email: "tag@stori.com",
+			Status: http.StatusOK, Message: "Summary sent",
+		},
+		{
+			Bucket: bucket, Key: "daily/john.doe@stori.com/july 2024.csv", Email: "john.doe@stori.com",
+			Status: http.StatusOK, Message: "Summary sent",
+		},
+		{
+			Bucket: bucket, Key: "daily/nobody.csv",
+			Status: http.StatusUnprocessableEntity, Code: "missing_recipient", Message: report.Records[3].Message,
+		},
+		{
+			Bucket: bucket, Key: "daily/missing.csv",
+			Status: http.StatusBadGateway, Code: "storage_unavailable", Message: report.Records[4].Message,
+		},
+		{
+			Bucket: bucket, Key: "daily/jane.doe@stori.com/invalid.csv", Email: "jane.doe@stori.com",
+			Status: http.StatusUnprocessableEntity, Code: "invalid_file", Message: report.Records[5].Message,
+		},
+	}, report.Records)
+	assert.Equal(t, []string{"metadata@stori.com", "tag@stori.com", "john.doe@stori.com"}, sent)
+}
+
+func TestInvoke_WhenS3KeyHasReservedCharacters_ReadsItsObject(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		key        string
+		encodedKey string
+	}{
+		{name: "When key has #", key: "statements/q1#2.csv", encodedKey: "statements/q1%232.csv"},
+		{name: "When key has ?", key: "x?y.csv", encodedKey: "x%3Fy.csv"},
+		{name: "When key has %", key: "100%.csv", encodedKey: "100%25.csv"},
+		{name: "When key has an escape", key: "a%20b.csv", encodedKey: "a%2520b.csv"},
+		{name: "When key has spaces", key: "july 2024.csv", encodedKey: "july+2024.csv"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			fake := test.NewFakeS3(t)
+			fake.Put(bucket, tc.key, test.FakeObject{
+				Content:  []byte("id,date,transaction\n0,7/15,+60.5\n"),
+				Metadata: map[string]string{"email": "john.doe@stori.com"},
+			})
+			// The objects a key cut short at # or ?, or unescaped twice, would be read instead.
+			for _, wrongKey := range []string{"statements/q1", "x", "a b.csv"} {
+				fake.Put(bucket, wrongKey, test.FakeObject{Content: []byte("not,a,statement\n")})
+			}
+
+			var sent []string
+			sut := s3Handler(t, fake, &sent)
+			config := filereader.Config{S3: filereader.S3Config{Endpoint: fake.URL(), PathStyle: true, Anonymous: true}}
+			sut.buildReader = func(filePath string) (accountsummary.TransactionsReader, error) {
+				return filereader.NewReader(filePath, config)
+			}
+
+			// Act
+			response, err := sut.invoke(context.Background(), s3Event(t, tc.encodedKey))
+
+			// Assert
+			require.NoError(t, err)
+			require.IsType(t, s3EventResponse{}, response)
+
+			report := response.(s3EventResponse) //nolint:forcetypeassert // Checked above
+			require.Len(t, report.Records, 1)
+			assert.Equal(t, tc.key, report.Records[0].Key)
+			assert.Equal(t, http.StatusOK, report.Records[0].Status, report.Records[0].Message)
+			assert.Equal(t, []string{"john.doe@stori.com"}, sent)
+		})
+	}
+}
+
+func TestInvoke_WhenHTTPRequest_HandledAsBefore(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	fake := test.NewFakeS3(t)
+
+	var sent []string
+	sut := s3Handler(t, fake, &sent)
+	payload := json.RawMessage(`{
+		"requestContext": {"requestId": "request-1"},
+		"body": "{\"filepath\": \"s3://statements/july.csv\", \"email\": \"john.doe@stori.com\"}"
+	}`)
+
+	// Act
+	response, err := sut.invoke(context.Background(), payload)
+
+	// Assert
+	require.NoError(t, err)
+	require.IsType(t, events.APIGatewayV2HTTPResponse{}, response)
+
+	httpResponse := response.(events.APIGatewayV2HTTPResponse) //nolint:forcetypeassert // Checked above
+	assert.Equal(t, http.StatusOK, httpResponse.StatusCode)
+	assert.Equal(t, []string{"john.doe@stori.com"}, sent)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+)
+
+const (
+	// sqsEventSource is the source of the records SQS delivers.
+	sqsEventSource = "aws:sqs"
+
+	// receiveCountAttribute is the number of times SQS delivered a message, this one included.
+	receiveCountAttribute = "ApproximateReceiveCount"
+
+	// DefaultMaxAttempts is the number of deliveries of a message before it is routed to the poison queue.
+	DefaultMaxAttempts = 5
+)
+
+// poisonRouter moves the messages that cannot be processed out of the queue, along with why they failed.
+type poisonRouter interface {
+	Route(ctx context.Context, message events.SQSMessage, err error) error
+}
+
+// handleSQSEvent processes every message of the batch, each a job like {"filepath": "s3://bucket/key", "email": "..."}.
+// Only the failed messages are reported, so that SQS deletes the others and delivers the failed ones again.
+// A message is poison when it can never be processed, i.e. it is invalid or its file cannot be summarized, or when it
+// failed on its last attempt. Poison messages are routed out of the queue, unless there is no poison queue, in which
+// case they are reported as failed for the redrive policy of the queue to deal with them.
+func (h handler) handleSQSEvent(ctx context.Context, event events.SQSEvent) events.SQSEventResponse {
+	response := events.SQSEventResponse{BatchItemFailures: []events.SQSBatchItemFailure{}}
+
+	for _, message := range event.Records {
+		if err := h.handleSQSMessage(ctx, message); err != nil {
+			response.BatchItemFailures = append(response.BatchItemFailures, events.SQSBatchItemFailure{
+				ItemIdentifier: message.MessageId,
+			})
+		}
+	}
+
+	return response
+}
+
+func (h handler) handleSQSMessage(ctx context.Context, message events.SQSMessage) error {
+	err := h.processSQSMessage(ctx, message)
+	if err == nil {
+		return nil
+	}
+
+	attempt := receiveCount(message)
+	log.Printf("message %s failed on attempt %d: %v", message.MessageId, attempt, err)
+
+	if h.poison == nil || (kindOf(err).status >= http.StatusInternalServerError && attempt < h.maxAttempts()) {
+		return err
+	}
+
+	if errRoute := h.poison.Route(ctx, message, err); errRoute != nil {
+		log.Printf("message %s could not be routed to the poison queue: %v", message.MessageId, errRoute)
+		return err
+	}
+
+	log.Printf("message %s routed to the poison queue", message.MessageId)
+
+	return nil
+}
+
+func (h handler) processSQSMessage(ctx context.Context, message events.SQSMessage) error {
+	email, filePath, err := parseJob(message.Body)
+	if err != nil {
+		return err
+	}
+
+	return h.process(ctx, email, filePath)
+}
+
+func (h handler) maxAttempts() int {
+	if h.sqsMaxAttempts <= 0 {
+		return DefaultMaxAttempts
+	}
+
+	return h.sqsMaxAttempts
+}
+
+// receiveCount is the attempt of the message, the first one when SQS did not tell.
+func receiveCount(message events.SQSMessage) int {
+	count, err := strconv.Atoi(message.Attributes[receiveCountAttribute])
+	if err != nil || count < 1 {
+		return 1
+	}
+
+	return count
+}
+
+// sqsPoisonQueue sends the poison messages to another queue, with the error code and message as attributes.
+type sqsPoisonQueue struct {
+	client   sqsiface.SQSAPI
+	queueURL string
+}
+
+func (queue sqsPoisonQueue) Route(ctx context.Context, message events.SQSMessage, err error) error {
+	fail := func(err error) error {
+		return fmt.Errorf("awslambda: sqsPoisonQueue: Route: %w", err)
+	}
+
+	stringAttribute := func(value string) *sqs.MessageAttributeValue {
+		return &sqs.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(value)}
+	}
+
+	_, errSend := queue.client.SendMessageWithContext(ctx, &sqs.SendMessageInput{
+		QueueUrl:    aws.String(queue.queueURL),
+		MessageBody: aws.String(message.Body),
+		MessageAttributes: map[string]*sqs.MessageAttributeValue{
+			"ErrorCode":       stringAttribute(kindOf(err).code),
+			"ErrorMessage":    stringAttribute(err.Error()),
+			"SourceMessageId": stringAttribute(message.MessageId),
+			"SourceQueue":     stringAttribute(message.EventSourceARN),
+			"ReceiveCount":    stringAttribute(strconv.Itoa(receiveCount(message))),
+		},
+	})
+	if errSend != nil {
+		return fail(errSend)
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"stori/accountsummary"
+	"stori/adapters/emailsender"
+	mocks "stori/mocks/stori/accountsummary"
+	"stori/model"
+	trans "stori/transactions"
+)
+
+const validJob = `{"filepath": "s3://statements/july.csv", "email": "john.doe@stori.com"}`
+
+// poisonQueueSpy keeps the messages routed to it, or fails with err.
+type poisonQueueSpy struct {
+	routed []string
+	err    error
+}
+
+func (spy *poisonQueueSpy) Route(_ context.Context, message events.SQSMessage, _ error) error {
+	if spy.err != nil {
+		return spy.err
+	}
+
+	spy.routed = append(spy.routed, message.MessageId)
+
+	return nil
+}
+
+// sqsHandler sends the summaries of the jobs, failing with sendErr for the emails in it.
+func sqsHandler(t *testing.T, sendErr map[string]error, poison poisonRouter) handler {
+	t.Helper()
+
+	transactions := []model.Transaction{
+		{ID: 1, Date: time.Date(2024, time.July, 15, 0, 0, 0, 0, time.UTC), Amount: decimal.MustParse("60.5")},
+	}
+
+	readerStub := mocks.NewMockTransactionsReader(t)
+	readerStub.EXPECT().StreamTransactions(mock.Anything).Return(trans.Seq(transactions)).Maybe()
+
+	emailSenderStub := mocks.NewMockEmailSender(t)
+	emailSenderStub.EXPECT().Send(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, summary model.AccountSummary) error {
+			return sendErr[summary.Email]
+		}).Maybe()
+
+	repositoryStub := mocks.NewMockRepository(t)
+	repositoryStub.EXPECT().Create(mock.Anything, mock.Anything).Return(int64(1), nil).Maybe()
+
+	return handler{
+		buildReader: func(string) (accountsummary.TransactionsReader, error) {
+			return readerStub, nil
+		},
+		buildEmailSender: func() (accountsummary.EmailSender, error) { return emailSenderStub, nil },
+		repository:       repositoryStub,
+		poison:           poison,
+		sqsMaxAttempts:   3,
+	}
+}
+
+func sqsMessage(id, body string, attempt int) events.SQSMessage {
+	return events.SQSMessage{
+		MessageId:   id,
+		Body:        body,
+		EventSource: sqsEventSource,
+		Attributes:  map[string]string{receiveCountAttribute: strconv.Itoa(attempt)},
+	}
+}
+
+func TestHandleSQSEvent(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		body             string
+		attempt          int
+		sendErr          error
+		noPoisonQueue    bool
+		routeErr         error
+		expectedFailures []string
+		expectedRouted   []string
+	}{
+		{name: "success", body: validJob, attempt: 1},
+		{
+			name: "transient error before the last attempt is retried", body: validJob, attempt: 2,
+			sendErr: emailsender.ErrSendEmail, expectedFailures: []string{"message-1"},
+		},
+		{
+			name: "transient error on the last attempt is routed", body: validJob, attempt: 3,
+			sendErr: emailsender.ErrSendEmail, expectedRouted: []string{"message-1"},
+		},
+		{
+			name: "invalid message is routed on its first attempt", body: `{"email": "john.doe@stori.com"}`, attempt: 1,
+			expectedRouted: []string{"message-1"},
+		},
+		{
+			name: "malformed message is routed on its first attempt", body: `{"filepath":`, attempt: 1,
+			expectedRouted: []string{"message-1"},
+		},
+		{
+			name: "poison message without poison queue is left to the redrive policy", body: `{"filepath":`,
+			attempt: 1, noPoisonQueue: true, expectedFailures: []string{"message-1"},
+		},
+		{
+			name: "poison message not routed is retried", body: `{"filepath":`, attempt: 1,
+			routeErr: errors.New("queue unavailable"), expectedFailures: []string{"message-1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			spy := &poisonQueueSpy{err: tc.routeErr}
+			var poison poisonRouter = spy
+			if tc.noPoisonQueue {
+				poison = nil
+			}
+
+			sut := sqsHandler(t, map[string]error{"john.doe@stori.com": tc.sendErr}, poison)
+			event := events.SQSEvent{Records: []events.SQSMessage{sqsMessage("message-1", tc.body, tc.attempt)}}
+
+			// Act
+			response := sut.handleSQSEvent(context.Background(), event)
+
+			// Assert
+			failures := make([]string, 0, len(response.BatchItemFailures))
+			for _, failure := range response.BatchItemFailures {
+				failures = append(failures, failure.ItemIdentifier)
+			}
+
+			assert.ElementsMatch(t, tc.expectedFailures, failures)
+			assert.ElementsMatch(t, tc.expectedRouted, spy.routed)
+		})
+	}
+}
+
+func TestInvoke_WhenSQSEvent_OnlyFailedMessagesReported(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	spy := &poisonQueueSpy{}
+	sut := sqsHandler(t, map[string]error{"jane.doe@stori.com": emailsender.ErrSendEmail}, spy)
+	payload, err := json.Marshal(events.SQSEvent{Records: []events.SQSMessage{
+		sqsMessage("message-1", validJob, 1),
+		sqsMessage("message-2", `{"filepath": "s3://statements/july.csv", "email": "jane.doe@stori.com"}`, 1),
+		sqsMessage("message-3", `not a job`, 1),
+		sqsMessage("message-4", validJob, 2),
+	}})
+	require.NoError(t, err)
+
+	// Act
+	response, err := sut.invoke(context.Background(), payload)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, events.SQSEventResponse{
+		BatchItemFailures: []events.SQSBatchItemFailure{{ItemIdentifier: "message-2"}},
+	}, response)
+	assert.Equal(t, []string{"message-3"}, spy.routed)
+}
+
+// sqsClientSpy keeps the messages sent to it.
+type sqsClientSpy struct {
+	sqsiface.SQSAPI
+	sent []*sqs.SendMessageInput
+}
+
+func (spy *sqsClientSpy) SendMessageWithContext(
+	_ aws.Context, input *sqs.SendMessageInput, _ ...request.Option,
+) (*sqs.SendMessageOutput, error) {
+	spy.sent = append(spy.sent, input)
+	return &sqs.SendMessageOutput{}, nil
+}
+
+func TestSQSPoisonQueueRoute(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	client := &sqsClientSpy{}
+	sut := sqsPoisonQueue{client: client, queueURL: "https://sqs.us-east-1.amazonaws.com/1/poison"}
+	message := sqsMessage("message-1", validJob, 3)
+	message.EventSourceARN = "arn:aws:sqs:us-east-1:1:jobs"
+
+	// Act
+	err := sut.Route(context.Background(), message, emailsender.ErrSendEmail)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, client.sent, 1)
+	assert.Equal(t, "https://sqs.us-east-1.amazonaws.com/1/poison", aws.StringValue(client.sent[0].QueueUrl))
+	assert.Equal(t, validJob, aws.StringValue(client.sent[0].MessageBody))
+
+	attributes := map[string]string{}
+	for name, value := range client.sent[0].MessageAttributes {
+		attributes[name] = aws.StringValue(value.StringValue)
+	}
+
+	assert.Equal(t, map[string]string{
+		"ErrorCode":       "email_unavailable",
+		"ErrorMessage":    emailsender.ErrSendEmail.Error(),
+		"SourceMessageId": "message-1",
+		"SourceQueue":     "arn:aws:sqs:us-east-1:1:jobs",
+		"ReceiveCount":    "3",
+	}, attributes)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"stori/accountsummary"
+	"stori/adapters/repository"
+)
+
+const dispatchCommand = "dispatch"
+
+// runDispatch sends the pending emails of the outbox, e.g. the ones that failed in previous runs. It has no preview
+// mode, as the emails of the outbox are marked as sent once they are handed to the sender.
+func runDispatch(ctx context.Context, args []string) error {
+	fail := func(err error) error {
+		return fmt.Errorf("main: runDispatch: %w", err)
+	}
+
+	flags := flag.NewFlagSet(dispatchCommand, flag.ExitOnError)
+	maxAttempts := flags.Int("max-attempts", accountsummary.DefaultMaxAttempts,
+		"Number of sends an email gets before it is marked as failed")
+	baseDelay := flags.Duration("base-delay", accountsummary.DefaultBaseDelay,
+		"Wait before retrying a failed email, doubled on every attempt")
+	maxDelay := flags.Duration("max-delay", accountsummary.DefaultMaxDelay, "Longest wait between two attempts")
+
+	if err := flags.Parse(args); err != nil {
+		return fail(err)
+	}
+
+	emailSender, err := buildEmailSender("")
+	if err != nil {
+		return fail(err)
+	}
+
+	db := setupDB(ctx)
+	if db == nil {
+		return fail(errors.New("the outbox needs a database"))
+	}
+	defer db.Close()
+
+	dispatcher := accountsummary.NewDispatcher(accountsummary.DispatcherConfig{
+		Outbox:      repository.New(db),
+		EmailSender: emailSender,
+		MaxAttempts: *maxAttempts,
+		BaseDelay:   *baseDelay,
+		MaxDelay:    *maxDelay,
+	})
+
+	report, err := dispatcher.Dispatch(ctx)
+	if err != nil {
+		return fail(err)
+	}
+
+	for _, errSend := range report.Errors {
+		fmt.Println(errSend)
+	}
+	fmt.Printf("%d emails sent, %d to be retried, %d failed\n", report.Sent, report.Retried, report.Failed)
+
+	return nil
+}
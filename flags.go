@@ -0,0 +1,204 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"stori/accountsummary"
+	"stori/adapters/filereader"
+)
+
+type readerFlags struct {
+	defaultYear    int
+	inferYear      bool
+	invalidRows    string
+	quarantinePath string
+	columnsPath    string
+	delimiter      string
+	idColumn       string
+	dateColumn     string
+	amountColumn   string
+	accountColumn  string
+	keepColumns    string
+	s3             filereader.S3Config
+	http           filereader.HTTPConfig
+	compression    filereader.CompressionConfig
+	zipMembers     string
+	format         string
+}
+
+func registerReaderFlags() *readerFlags {
+	options := &readerFlags{}
+
+	flag.IntVar(&options.defaultYear, "year", 0,
+		"Year given to dates without one. Defaults to the current year")
+	flag.BoolVar(&options.inferYear, "infer-year", false,
+		"Move dates without a year to the next one whenever the month goes backwards, starting at -year")
+	flag.StringVar(&options.format, "format", "",
+		"Format of the file: csv, ofx or json. Detected from its name, its type or its content when empty")
+	flag.StringVar(&options.invalidRows, "invalid-rows", "reject",
+		"What to do with invalid rows: reject the file, skip them or quarantine them to -quarantine")
+	flag.StringVar(&options.quarantinePath, "quarantine", "quarantine.csv",
+		"CSV filepath where invalid rows are written when -invalid-rows is quarantine, one per member with "+
+			"-zip-members=separate")
+	flag.StringVar(&options.columnsPath, "columns", "",
+		"JSON file with the column mapping of the input. The flags below override it")
+	flag.StringVar(&options.delimiter, "delimiter", "", "Column delimiter of the input, e.g. ';' or '\\t'")
+	flag.StringVar(&options.idColumn, "id-column", "", "Name and aliases of the id column, e.g. 'id|ref'")
+	flag.StringVar(&options.dateColumn, "date-column", "", "Name and aliases of the date column, e.g. 'date|fecha'")
+	flag.StringVar(&options.amountColumn, "amount-column", "",
+		"Name and aliases of the amount column, e.g. 'transaction|amount'")
+	flag.StringVar(&options.accountColumn, "account-column", "",
+		"Name and aliases of the account column of consolidated files, e.g. 'account|customer'")
+	flag.StringVar(&options.keepColumns, "keep-columns", "",
+		"Comma separated extra columns kept with each transaction, e.g. 'description,merchant'")
+	flag.StringVar(&options.s3.Region, "s3-region", "",
+		"Region of the S3 bucket. Defaults to the region of the environment or the profile, else us-east-1")
+	flag.StringVar(&options.s3.Profile, "s3-profile", "", "AWS profile used to read S3 objects")
+	flag.BoolVar(&options.s3.Anonymous, "s3-anonymous", false, "Read public S3 buckets without credentials")
+	flag.StringVar(&options.s3.Endpoint, "s3-endpoint", "",
+		"S3 endpoint override, e.g. http://localhost:9000 for MinIO or LocalStack")
+	flag.BoolVar(&options.s3.PathStyle, "s3-path-style", false,
+		"Address S3 buckets in the path of the endpoint, as MinIO needs")
+	flag.Int64Var(&options.s3.MaxObjectSize, "s3-max-object-size", filereader.DefaultMaxObjectSize,
+		"Size in bytes of the largest S3 object read. Negative to read objects whatever their size")
+	flag.Int64Var(&options.s3.PartSize, "s3-part-size", filereader.DefaultPartSize,
+		"Size in bytes of the ranges S3 objects are read by")
+	flag.DurationVar(&options.http.Timeout, "http-timeout", filereader.DefaultHTTPTimeout,
+		"Time allowed to download a file from an http:// or https:// URL")
+	flag.Int64Var(&options.http.MaxBodySize, "http-max-size", filereader.DefaultMaxObjectSize,
+		"Size in bytes of the largest file downloaded from a URL. Negative to download files whatever their size")
+	flag.StringVar(&options.zipMembers, "zip-members", "concatenate",
+		"How the members of zip archives are read: concatenate them as one file, or separate them as one input each")
+	flag.Int64Var(&options.compression.MaxDecompressedSize, "max-decompressed-size",
+		filereader.DefaultMaxDecompressedSize,
+		"Size in bytes of the content decompressed from a file at most. Negative to decompress files whatever their size")
+	flag.Int64Var(&options.compression.MaxRatio, "max-compression-ratio", filereader.DefaultMaxRatio,
+		"How many times larger than the compressed file its content can be. Negative for no limit")
+	flag.IntVar(&options.compression.MaxMembers, "max-zip-members", filereader.DefaultMaxMembers,
+		"Number of files a zip archive can hold at most. Negative for no limit")
+
+	return options
+}
+
+func (options *readerFlags) config() (filereader.Config, error) {
+	fail := func(err error) (filereader.Config, error) {
+		return filereader.Config{}, fmt.Errorf("main: readerFlags: config: %w", err)
+	}
+
+	config := filereader.Config{
+		DefaultYear:    options.defaultYear,
+		QuarantinePath: options.quarantinePath,
+		S3:             options.s3,
+		HTTP:           options.http,
+		Compression:    options.compression,
+		Format:         filereader.Format(options.format),
+	}
+
+	if options.inferYear {
+		config.YearInference = filereader.YearRollover
+	}
+
+	policy, err := parseInvalidRowPolicy(options.invalidRows)
+	if err != nil {
+		return fail(err)
+	}
+	config.InvalidRows = policy
+
+	if config.Compression.Members, err = parseMemberPolicy(options.zipMembers); err != nil {
+		return fail(err)
+	}
+
+	if config.Columns, err = options.columnMapping(); err != nil {
+		return fail(err)
+	}
+
+	return config, nil
+}
+
+func (options *readerFlags) columnMapping() (filereader.ColumnMapping, error) {
+	mapping := filereader.ColumnMapping{}
+	if options.isDefaultMapping() {
+		return mapping, nil
+	}
+
+	mapping = filereader.DefaultColumnMapping()
+	if options.columnsPath != "" {
+		var err error
+		if mapping, err = filereader.LoadColumnMapping(options.columnsPath); err != nil {
+			return filereader.ColumnMapping{}, err
+		}
+	}
+
+	if options.delimiter != "" {
+		delimiter, err := filereader.ParseDelimiter(options.delimiter)
+		if err != nil {
+			return filereader.ColumnMapping{}, err
+		}
+		mapping.Delimiter = delimiter
+	}
+
+	if options.idColumn != "" {
+		mapping.ID = filereader.ParseColumn(options.idColumn)
+	}
+
+	if options.dateColumn != "" {
+		mapping.Date = filereader.ParseColumn(options.dateColumn)
+	}
+
+	if options.amountColumn != "" {
+		mapping.Amount = filereader.ParseColumn(options.amountColumn)
+	}
+
+	if options.accountColumn != "" {
+		mapping.Account = filereader.ParseColumn(options.accountColumn)
+	}
+
+	if options.keepColumns != "" {
+		mapping.Keep = strings.Split(options.keepColumns, ",")
+	}
+
+	return mapping, nil
+}
+
+func (options *readerFlags) isDefaultMapping() bool {
+	return options.columnsPath == "" && options.delimiter == "" && options.idColumn == "" &&
+		options.dateColumn == "" && options.amountColumn == "" && options.accountColumn == "" &&
+		options.keepColumns == ""
+}
+
+func parseInvalidRowPolicy(name string) (filereader.InvalidRowPolicy, error) {
+	switch name {
+	case "reject":
+		return filereader.RejectFile, nil
+	case "skip":
+		return filereader.SkipInvalidRows, nil
+	case "quarantine":
+		return filereader.QuarantineInvalidRows, nil
+	default:
+		return 0, fmt.Errorf("unknown invalid rows policy: %s", name)
+	}
+}
+
+func parseMemberPolicy(name string) (filereader.MemberPolicy, error) {
+	switch name {
+	case "concatenate":
+		return filereader.ConcatenateMembers, nil
+	case "separate":
+		return filereader.SeparateMembers, nil
+	default:
+		return 0, fmt.Errorf("unknown zip members policy: %s", name)
+	}
+}
+
+func parseDuplicatePolicy(name string) (accountsummary.DuplicatePolicy, error) {
+	switch name {
+	case "skip":
+		return accountsummary.SkipDuplicates, nil
+	case "reject":
+		return accountsummary.RejectDuplicates, nil
+	default:
+		return 0, fmt.Errorf("unknown duplicate policy: %s", name)
+	}
+}
@@ -1,18 +1,22 @@
 package main
 
 import (
+	"context"
 	"embed"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"strconv"
-	"strings"
+	"syscall"
 
 	"github.com/jmoiron/sqlx"
 
 	"stori/accountsummary"
 	"stori/adapters/emailsender"
 	"stori/adapters/filereader"
+	"stori/adapters/recipients"
 	"stori/adapters/repository"
 
 	_ "github.com/lib/pq"
@@ -20,12 +24,15 @@ import (
 )
 
 const (
-	s3FilePathPrefix = "s3://"
-
 	EmailHost     = "EMAIL_HOST"
 	EmailPort     = "EMAIL_PORT"
 	EmailUsername = "EMAIL_USERNAME"
 	EmailPassword = "EMAIL_PASSWORD"
+	EmailLocale   = "EMAIL_LOCALE"
+
+	HTTPBearerToken = "HTTP_BEARER_TOKEN"
+	HTTPUsername    = "HTTP_USERNAME"
+	HTTPPassword    = "HTTP_PASSWORD"
 
 	migrationsDir = "migrations"
 )
@@ -34,45 +41,230 @@ const (
 var embedMigrations embed.FS
 
 func main() {
-	var email, filepath string
+	// SIGINT and SIGTERM cancel the work in flight: the execution being stored is rolled back, and emails being sent
+	// stay in the outbox.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if len(os.Args) > 1 && os.Args[1] == dispatchCommand {
+		if err := runDispatch(ctx, os.Args[2:]); err != nil {
+			exit(ctx, err)
+		}
+
+		return
+	}
+
+	var email, filepath, recipientsPath, previewDir string
+	var validateOnly, force bool
+	var onDuplicate string
+	var batchSize, copyThreshold int
 	flag.StringVar(&email, "email", "", "Email address where the results of the process will be sent")
-	flag.StringVar(&filepath, "filepath", "", "CSV filepath with transactions to be processed")
+	flag.StringVar(&filepath, "filepath", "",
+		"Local path, s3:// URI or http(s):// URL of the file with the transactions to be processed")
+	flag.StringVar(&recipientsPath, "recipients", "",
+		"CSV file with the email of each account, with an 'account,email' header. "+
+			"Enables batch mode: one summary per account of -account-column, instead of one sent to -email")
+	flag.StringVar(&previewDir, "preview-dir", "",
+		"Directory where the emails are written as .eml and .html files instead of being sent")
+	flag.BoolVar(&validateOnly, "validate", false,
+		"Only report every invalid row of the file, without processing it")
+	flag.BoolVar(&force, "force", false,
+		"Process the file again even if it was already summarized for the email")
+	flag.StringVar(&onDuplicate, "on-duplicate", "skip",
+		"What to do when the file was already summarized for the email: skip it or reject it with an error")
+	flag.IntVar(&batchSize, "batch-size", repository.DefaultBatchSize,
+		"Number of transactions stored per insert statement")
+	flag.IntVar(&copyThreshold, "copy-threshold", repository.DefaultCopyThreshold,
+		"Number of transactions from which they are stored with COPY instead of batched inserts. -1 disables COPY")
+	readerOptions := registerReaderFlags()
 	flag.Parse()
 
-	reader := buildTransactionsReader(filepath)
+	readerConfig, err := readerOptions.config()
+	if err != nil {
+		panic(err)
+	}
+
+	readerConfig.HTTP.BearerToken = os.Getenv(HTTPBearerToken)
+	readerConfig.HTTP.Username = os.Getenv(HTTPUsername)
+	readerConfig.HTTP.Password = os.Getenv(HTTPPassword)
+
+	inputs, err := filereader.Inputs(ctx, filepath, readerConfig)
+	if err != nil {
+		exit(ctx, err)
+	}
+
+	if validateOnly {
+		valid, errValidate := validateInputs(ctx, inputs)
+		if errValidate != nil {
+			exit(ctx, errValidate)
+		}
+
+		if !valid {
+			os.Exit(1)
+		}
+
+		return
+	}
 
-	emailSender, err := buildEmailSender()
+	duplicatePolicy, err := parseDuplicatePolicy(onDuplicate)
 	if err != nil {
 		panic(err)
 	}
 
-	db := setupDB()
-	defer db.Close()
+	emailSender, err := buildEmailSender(previewDir)
+	if err != nil {
+		panic(err)
+	}
+
+	// Previews are dry runs: nothing is stored, so that a file previewed is not taken as processed afterwards and its
+	// email is not queued in the outbox.
+	var db *sqlx.DB
+	if previewDir == "" {
+		db = setupDB(ctx)
+	}
+
+	if db != nil {
+		defer db.Close()
+	}
 
 	repo := repository.New(db)
+	repo.BatchSize = batchSize
+	repo.CopyThreshold = copyThreshold
 
-	application := accountsummary.New(accountsummary.Config{
-		Email:              email,
-		FilePath:           filepath,
-		TransactionsReader: reader,
-		EmailSender:        emailSender,
-		Repository:         repo,
-	})
+	var outbox accountsummary.Outbox
+	if db != nil {
+		outbox = repo
+	}
+
+	// Each member of a zip archive is an input of its own with -zip-members=separate, processed one after the other.
+	succeeded := true
+	for _, input := range inputs {
+		application := accountsummary.New(accountsummary.Config{
+			Email:              email,
+			FilePath:           filereader.RedactURI(input.URI),
+			TransactionsReader: input.Reader,
+			EmailSender:        emailSender,
+			Repository:         repo,
+			OnDuplicate:        duplicatePolicy,
+			Force:              force,
+			Outbox:             outbox,
+		})
+
+		if recipientsPath != "" {
+			printInput(inputs, input)
+
+			inputSucceeded, errBatch := runBatch(ctx, application, recipientsPath)
+			if errBatch != nil {
+				exit(ctx, errBatch)
+			}
+
+			succeeded = succeeded && inputSucceeded
+
+			continue
+		}
+
+		// The summary of an email not sent is already stored, and its email queued: it is reported rather than
+		// panicking, and the other inputs are still processed.
+		if errRun := application.Run(ctx); errors.Is(errRun, accountsummary.ErrEmailNotSent) {
+			fmt.Fprintf(os.Stderr, "%v\nthe email is queued to be sent by `stori %s`\n", errRun, dispatchCommand)
+			succeeded = false
+		} else if errRun != nil {
+			exit(ctx, errRun)
+		}
+	}
 
-	if errRun := application.Run(); errRun != nil {
-		panic(errRun)
+	if !succeeded {
+		os.Exit(1)
 	}
 }
 
-func buildTransactionsReader(filepath string) accountsummary.TransactionsReader {
-	if strings.HasPrefix(filepath, s3FilePathPrefix) {
-		return filereader.NewS3Reader(filepath)
+// exit panics with the error, unless the run was interrupted, which is reported without a stack trace.
+func exit(ctx context.Context, err error) {
+	if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+		fmt.Fprintln(os.Stderr, "interrupted:", err)
+		os.Exit(1)
 	}
 
-	return filereader.NewLocalReader(filepath)
+	panic(err)
 }
 
-func buildEmailSender() (accountsummary.EmailSender, error) {
+func runBatch(ctx context.Context, application accountsummary.App, recipientsPath string) (bool, error) {
+	directory, err := recipients.Load(recipientsPath)
+	if err != nil {
+		return false, err
+	}
+
+	application.Recipients = directory
+
+	report, err := application.RunBatch(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	failed, skipped := report.Failed(), report.Skipped()
+	for _, result := range failed {
+		fmt.Println(result.Err)
+	}
+	fmt.Printf("%d accounts summarized, %d already processed, %d failed\n",
+		len(report.Results)-len(failed)-len(skipped), len(skipped), len(failed))
+
+	return len(failed) == 0, nil
+}
+
+// validateInputs reports the invalid rows of every input, telling whether they are all valid.
+func validateInputs(ctx context.Context, inputs []filereader.Input) (bool, error) {
+	valid := true
+	for _, input := range inputs {
+		printInput(inputs, input)
+
+		inputValid, err := validateTransactions(ctx, input.Reader)
+		if err != nil {
+			return false, err
+		}
+
+		valid = valid && inputValid
+	}
+
+	return valid, nil
+}
+
+func validateTransactions(ctx context.Context, reader accountsummary.TransactionsReader) (bool, error) {
+	validator, ok := reader.(interface {
+		Validate(ctx context.Context) (filereader.ValidationReport, error)
+	})
+	if !ok {
+		return false, fmt.Errorf("validation is not supported by %T", reader)
+	}
+
+	report, err := validator.Validate(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, rowErr := range report.Errors {
+		fmt.Println(rowErr)
+	}
+	fmt.Printf("%d rows scanned, %d errors found\n", report.Rows, len(report.Errors))
+
+	return report.Valid(), nil
+}
+
+// printInput names the input its report is about, when there are several of them.
+func printInput(inputs []filereader.Input, input filereader.Input) {
+	if len(inputs) > 1 {
+		fmt.Printf("%s:\n", input.URI)
+	}
+}
+
+// buildEmailSender writes the emails to previewDir when given, and sends them over SMTP otherwise.
+func buildEmailSender(previewDir string) (accountsummary.EmailSender, error) {
+	if previewDir != "" {
+		return emailsender.NewPreview(emailsender.Config{
+			Username: os.Getenv(EmailUsername),
+			Locale:   os.Getenv(EmailLocale),
+		}, previewDir), nil
+	}
+
 	host := os.Getenv(EmailHost)
 	port, err := strconv.Atoi(os.Getenv(EmailPort))
 	if err != nil {
@@ -81,16 +273,18 @@ func buildEmailSender() (accountsummary.EmailSender, error) {
 
 	username := os.Getenv(EmailUsername)
 	password := os.Getenv(EmailPassword)
+	locale := os.Getenv(EmailLocale)
 
 	return emailsender.New(emailsender.Config{
 		Host:     host,
 		Port:     port,
 		Username: username,
 		Password: password,
+		Locale:   locale,
 	}), nil
 }
 
-func setupDB() *sqlx.DB {
+func setupDB(ctx context.Context) *sqlx.DB {
 	dbUser := os.Getenv("DB_USER")
 	dbPass := os.Getenv("DB_PASS")
 	dbName := os.Getenv("DB_NAME")
@@ -101,26 +295,26 @@ func setupDB() *sqlx.DB {
 
 	dsn := fmt.Sprintf("dbname=%s user=%s password=%s host=%s sslmode=disable", dbName, dbUser, dbPass, dbHost)
 
-	db, err := sqlx.Connect("postgres", dsn)
+	db, err := sqlx.ConnectContext(ctx, "postgres", dsn)
 	if err != nil {
 		return nil
 	}
 
-	if err = runMigrations(db); err != nil {
+	if err = runMigrations(ctx, db); err != nil {
 		return nil
 	}
 
 	return db
 }
 
-func runMigrations(db *sqlx.DB) error {
+func runMigrations(ctx context.Context, db *sqlx.DB) error {
 	goose.SetBaseFS(embedMigrations)
 
 	if err := goose.SetDialect("postgres"); err != nil {
 		return err
 	}
 
-	if err := goose.Up(db.DB, migrationsDir); err != nil {
+	if err := goose.UpContext(ctx, db.DB, migrationsDir); err != nil {
 		return err
 	}
 
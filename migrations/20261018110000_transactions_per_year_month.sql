@@ -0,0 +1,27 @@
+-- +goose Up
+-- Transactions per month were keyed by month number only, e.g. {"1": 3}. They are now keyed by year and month,
+-- e.g. {"2024-01": 3}. The year is recovered from the transactions stored for the same file and month,
+-- falling back to the current year when none is found.
+update ACCOUNT_SUMMARY s
+set TRANSACTIONS_PER_MONTH = (
+    select coalesce(jsonb_object_agg(
+        lpad(coalesce(
+            (select min(extract(year from t.DATE))::int
+             from TRANSACTION t
+             where t.FILE_PATH = s.FILE_PATH
+               and extract(month from t.DATE) = m.key::int),
+            extract(year from current_date)::int)::text, 4, '0') || '-' || lpad(m.key, 2, '0'),
+        m.value), '{}'::jsonb)
+    from jsonb_each(s.TRANSACTIONS_PER_MONTH) m
+)
+where exists (select 1 from jsonb_object_keys(s.TRANSACTIONS_PER_MONTH) k where k ~ '^[0-9]{1,2}$');
+
+-- +goose Down
+update ACCOUNT_SUMMARY s
+set TRANSACTIONS_PER_MONTH = (
+    select coalesce(jsonb_object_agg(m.month, m.total), '{}'::jsonb)
+    from (select split_part(e.key, '-', 2)::int::text as month, sum(e.value::int) as total
+          from jsonb_each_text(s.TRANSACTIONS_PER_MONTH) e
+          group by 1) m
+)
+where exists (select 1 from jsonb_object_keys(s.TRANSACTIONS_PER_MONTH) k where k ~ '^[0-9]{4}-[0-9]{2}$');
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Summaries created before balances were tracked day by day keep these columns null.
+alter table ACCOUNT_SUMMARY
+    add column MINIMUM_BALANCE decimal,
+    add column MINIMUM_BALANCE_DATE date,
+    add column MAXIMUM_BALANCE decimal,
+    add column MAXIMUM_BALANCE_DATE date,
+    add column DAILY_BALANCES jsonb;
+
+-- +goose Down
+alter table ACCOUNT_SUMMARY
+    drop column if exists MINIMUM_BALANCE,
+    drop column if exists MINIMUM_BALANCE_DATE,
+    drop column if exists MAXIMUM_BALANCE,
+    drop column if exists MAXIMUM_BALANCE_DATE,
+    drop column if exists DAILY_BALANCES;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Credits and debits per month, as a JSON array ordered by month. Null for summaries created before it existed.
+alter table ACCOUNT_SUMMARY
+    add column MONTHLY_BREAKDOWN jsonb;
+
+-- +goose Down
+alter table ACCOUNT_SUMMARY
+    drop column if exists MONTHLY_BREAKDOWN;
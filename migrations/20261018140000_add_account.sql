@@ -0,0 +1,14 @@
+-- +goose Up
+-- The account or customer of consolidated files. Null for single account files.
+alter table ACCOUNT_SUMMARY
+    add column ACCOUNT varchar(255);
+
+alter table TRANSACTION
+    add column ACCOUNT varchar(255);
+
+-- +goose Down
+alter table TRANSACTION
+    drop column if exists ACCOUNT;
+
+alter table ACCOUNT_SUMMARY
+    drop column if exists ACCOUNT;
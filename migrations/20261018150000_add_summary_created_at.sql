@@ -0,0 +1,12 @@
+-- +goose Up
+-- The time each summary was created, i.e. the time of the execution. Existing summaries get the migration time.
+alter table ACCOUNT_SUMMARY
+    add column CREATED_AT timestamptz not null default now();
+
+create index ACCOUNT_SUMMARY_EMAIL_CREATED_AT_IDX on ACCOUNT_SUMMARY (EMAIL, CREATED_AT desc);
+
+-- +goose Down
+drop index if exists ACCOUNT_SUMMARY_EMAIL_CREATED_AT_IDX;
+
+alter table ACCOUNT_SUMMARY
+    drop column if exists CREATED_AT;
@@ -0,0 +1,89 @@
+-- +goose Up
+-- An execution is a run of the app over a source. Summaries and transactions were only linked through their
+-- FILE_PATH, which mixed the rows of a file processed twice; they now reference the execution that created them.
+create table EXECUTION
+(
+    ID          uuid primary key,
+    SOURCE_URI  varchar(1024) not null,
+    CHECKSUM    varchar(128),
+    STATUS      varchar(32)   not null,
+    STARTED_AT  timestamptz   not null,
+    FINISHED_AT timestamptz
+);
+
+alter table ACCOUNT_SUMMARY
+    add column EXECUTION_ID uuid references EXECUTION (ID);
+
+-- SOURCE_ID is the id the transaction had in its source. It is unknown for transactions stored before.
+alter table TRANSACTION
+    add column EXECUTION_ID uuid references EXECUTION (ID),
+    add column SOURCE_ID    bigint;
+
+-- Every existing summary becomes an execution of its own. gen_random_uuid is not available before PostgreSQL 13,
+-- hence the UUIDs derived from md5.
+create temporary table SUMMARY_EXECUTION as
+select ID                                                                  as SUMMARY_ID,
+       md5(random()::text || clock_timestamp()::text || ID::text)::uuid    as EXECUTION_ID,
+       FILE_PATH,
+       ACCOUNT,
+       CREATED_AT
+from ACCOUNT_SUMMARY;
+
+insert into EXECUTION (ID, SOURCE_URI, STATUS, STARTED_AT, FINISHED_AT)
+select EXECUTION_ID, FILE_PATH, 'completed', CREATED_AT, CREATED_AT
+from SUMMARY_EXECUTION;
+
+update ACCOUNT_SUMMARY s
+set EXECUTION_ID = se.EXECUTION_ID
+from SUMMARY_EXECUTION se
+where se.SUMMARY_ID = s.ID;
+
+-- Transactions go to the earliest execution of the same file and account.
+update TRANSACTION t
+set EXECUTION_ID = (select se.EXECUTION_ID
+                    from SUMMARY_EXECUTION se
+                    where se.FILE_PATH = t.FILE_PATH
+                      and se.ACCOUNT is not distinct from t.ACCOUNT
+                    order by se.CREATED_AT, se.SUMMARY_ID
+                    limit 1);
+
+-- Transactions without any summary get an execution per file and account.
+create temporary table ORPHAN_EXECUTION as
+select FILE_PATH,
+       ACCOUNT,
+       md5(random()::text || clock_timestamp()::text || FILE_PATH || coalesce(ACCOUNT, ''))::uuid as EXECUTION_ID
+from (select distinct FILE_PATH, ACCOUNT from TRANSACTION where EXECUTION_ID is null) orphans;
+
+insert into EXECUTION (ID, SOURCE_URI, STATUS, STARTED_AT, FINISHED_AT)
+select EXECUTION_ID, FILE_PATH, 'completed', now(), now()
+from ORPHAN_EXECUTION;
+
+update TRANSACTION t
+set EXECUTION_ID = oe.EXECUTION_ID
+from ORPHAN_EXECUTION oe
+where t.EXECUTION_ID is null
+  and oe.FILE_PATH = t.FILE_PATH
+  and oe.ACCOUNT is not distinct from t.ACCOUNT;
+
+drop table SUMMARY_EXECUTION;
+drop table ORPHAN_EXECUTION;
+
+alter table ACCOUNT_SUMMARY
+    alter column EXECUTION_ID set not null;
+
+alter table TRANSACTION
+    alter column EXECUTION_ID set not null;
+
+create index TRANSACTION_EXECUTION_ID_IDX on TRANSACTION (EXECUTION_ID);
+
+-- +goose Down
+drop index if exists TRANSACTION_EXECUTION_ID_IDX;
+
+alter table TRANSACTION
+    drop column if exists SOURCE_ID,
+    drop column if exists EXECUTION_ID;
+
+alter table ACCOUNT_SUMMARY
+    drop column if exists EXECUTION_ID;
+
+drop table if exists EXECUTION;
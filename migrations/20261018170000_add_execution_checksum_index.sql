@@ -0,0 +1,6 @@
+-- +goose Up
+-- Executions are looked up by checksum to skip the sources that were already processed.
+create index EXECUTION_CHECKSUM_IDX on EXECUTION (CHECKSUM);
+
+-- +goose Down
+drop index if exists EXECUTION_CHECKSUM_IDX;
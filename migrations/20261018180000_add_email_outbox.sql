@@ -0,0 +1,19 @@
+-- +goose Up
+-- Emails are queued in the same db transaction as their summary, and sent by the dispatcher once committed.
+-- Summaries stored before the outbox existed were already sent, so they are not queued.
+create table EMAIL_OUTBOX
+(
+    ID                 bigserial primary key,
+    ACCOUNT_SUMMARY_ID integer     not null references ACCOUNT_SUMMARY (ID),
+    STATUS             varchar(16) not null default 'pending',
+    ATTEMPTS           integer     not null default 0,
+    LAST_ERROR         text,
+    NEXT_ATTEMPT_AT    timestamptz not null default now(),
+    CREATED_AT         timestamptz not null default now(),
+    SENT_AT            timestamptz
+);
+
+create index EMAIL_OUTBOX_PENDING_IDX on EMAIL_OUTBOX (NEXT_ATTEMPT_AT) where STATUS = 'pending';
+
+-- +goose Down
+drop table if exists EMAIL_OUTBOX;
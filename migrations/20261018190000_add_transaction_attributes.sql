@@ -0,0 +1,9 @@
+-- +goose Up
+-- Extra columns of the source listed in the keep of the column mapping, as a JSON object by column name. Null when
+-- none was kept.
+alter table TRANSACTION
+    add column ATTRIBUTES jsonb;
+
+-- +goose Down
+alter table TRANSACTION
+    drop column if exists ATTRIBUTES;
@@ -0,0 +1,92 @@
+// Code generated by mockery v2.46.3. DO NOT EDIT.
+
+package accountsummary
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockChecksummer is an autogenerated mock type for the Checksummer type
+type MockChecksummer struct {
+	mock.Mock
+}
+
+type MockChecksummer_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockChecksummer) EXPECT() *MockChecksummer_Expecter {
+	return &MockChecksummer_Expecter{mock: &_m.Mock}
+}
+
+// Checksum provides a mock function with given fields: ctx
+func (_m *MockChecksummer) Checksum(ctx context.Context) (string, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Checksum")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (string, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) string); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockChecksummer_Checksum_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Checksum'
+type MockChecksummer_Checksum_Call struct {
+	*mock.Call
+}
+
+// Checksum is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockChecksummer_Expecter) Checksum(ctx interface{}) *MockChecksummer_Checksum_Call {
+	return &MockChecksummer_Checksum_Call{Call: _e.mock.On("Checksum", ctx)}
+}
+
+func (_c *MockChecksummer_Checksum_Call) Run(run func(ctx context.Context)) *MockChecksummer_Checksum_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockChecksummer_Checksum_Call) Return(_a0 string, _a1 error) *MockChecksummer_Checksum_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockChecksummer_Checksum_Call) RunAndReturn(run func(context.Context) (string, error)) *MockChecksummer_Checksum_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockChecksummer creates a new instance of MockChecksummer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockChecksummer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockChecksummer {
+	mock := &MockChecksummer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -3,6 +3,7 @@
 package accountsummary
 
 import (
+	context "context"
 	model "stori/model"
 
 	mock "github.com/stretchr/testify/mock"
@@ -21,17 +22,17 @@ func (_m *MockEmailSender) EXPECT() *MockEmailSender_Expecter {
 	return &MockEmailSender_Expecter{mock: &_m.Mock}
 }
 
-// Send provides a mock function with given fields: summary
-func (_m *MockEmailSender) Send(summary model.AccountSummary) error {
-	ret := _m.Called(summary)
+// Send provides a mock function with given fields: ctx, summary
+func (_m *MockEmailSender) Send(ctx context.Context, summary model.AccountSummary) error {
+	ret := _m.Called(ctx, summary)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Send")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(model.AccountSummary) error); ok {
-		r0 = rf(summary)
+	if rf, ok := ret.Get(0).(func(context.Context, model.AccountSummary) error); ok {
+		r0 = rf(ctx, summary)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -45,14 +46,15 @@ type MockEmailSender_Send_Call struct {
 }
 
 // Send is a helper method to define mock.On call
+//   - ctx context.Context
 //   - summary model.AccountSummary
-func (_e *MockEmailSender_Expecter) Send(summary interface{}) *MockEmailSender_Send_Call {
-	return &MockEmailSender_Send_Call{Call: _e.mock.On("Send", summary)}
+func (_e *MockEmailSender_Expecter) Send(ctx interface{}, summary interface{}) *MockEmailSender_Send_Call {
+	return &MockEmailSender_Send_Call{Call: _e.mock.On("Send", ctx, summary)}
 }
 
-func (_c *MockEmailSender_Send_Call) Run(run func(summary model.AccountSummary)) *MockEmailSender_Send_Call {
+func (_c *MockEmailSender_Send_Call) Run(run func(ctx context.Context, summary model.AccountSummary)) *MockEmailSender_Send_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(model.AccountSummary))
+		run(args[0].(context.Context), args[1].(model.AccountSummary))
 	})
 	return _c
 }
@@ -62,7 +64,7 @@ func (_c *MockEmailSender_Send_Call) Return(_a0 error) *MockEmailSender_Send_Cal
 	return _c
 }
 
-func (_c *MockEmailSender_Send_Call) RunAndReturn(run func(model.AccountSummary) error) *MockEmailSender_Send_Call {
+func (_c *MockEmailSender_Send_Call) RunAndReturn(run func(context.Context, model.AccountSummary) error) *MockEmailSender_Send_Call {
 	_c.Call.Return(run)
 	return _c
 }
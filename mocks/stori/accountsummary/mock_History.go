@@ -0,0 +1,214 @@
+// Code generated by mockery v2.46.3. DO NOT EDIT.
+
+package accountsummary
+
+import (
+	context "context"
+	accountsummary "stori/accountsummary"
+
+	mock "github.com/stretchr/testify/mock"
+
+	model "stori/model"
+)
+
+// MockHistory is an autogenerated mock type for the History type
+type MockHistory struct {
+	mock.Mock
+}
+
+type MockHistory_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockHistory) EXPECT() *MockHistory_Expecter {
+	return &MockHistory_Expecter{mock: &_m.Mock}
+}
+
+// GetSummary provides a mock function with given fields: ctx, id
+func (_m *MockHistory) GetSummary(ctx context.Context, id int64) (model.StoredSummary, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSummary")
+	}
+
+	var r0 model.StoredSummary
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (model.StoredSummary, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) model.StoredSummary); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(model.StoredSummary)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockHistory_GetSummary_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSummary'
+type MockHistory_GetSummary_Call struct {
+	*mock.Call
+}
+
+// GetSummary is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+func (_e *MockHistory_Expecter) GetSummary(ctx interface{}, id interface{}) *MockHistory_GetSummary_Call {
+	return &MockHistory_GetSummary_Call{Call: _e.mock.On("GetSummary", ctx, id)}
+}
+
+func (_c *MockHistory_GetSummary_Call) Run(run func(ctx context.Context, id int64)) *MockHistory_GetSummary_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockHistory_GetSummary_Call) Return(_a0 model.StoredSummary, _a1 error) *MockHistory_GetSummary_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockHistory_GetSummary_Call) RunAndReturn(run func(context.Context, int64) (model.StoredSummary, error)) *MockHistory_GetSummary_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListSummaries provides a mock function with given fields: ctx, filter
+func (_m *MockHistory) ListSummaries(ctx context.Context, filter accountsummary.SummaryFilter) ([]model.StoredSummary, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSummaries")
+	}
+
+	var r0 []model.StoredSummary
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, accountsummary.SummaryFilter) ([]model.StoredSummary, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, accountsummary.SummaryFilter) []model.StoredSummary); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.StoredSummary)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, accountsummary.SummaryFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockHistory_ListSummaries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListSummaries'
+type MockHistory_ListSummaries_Call struct {
+	*mock.Call
+}
+
+// ListSummaries is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter accountsummary.SummaryFilter
+func (_e *MockHistory_Expecter) ListSummaries(ctx interface{}, filter interface{}) *MockHistory_ListSummaries_Call {
+	return &MockHistory_ListSummaries_Call{Call: _e.mock.On("ListSummaries", ctx, filter)}
+}
+
+func (_c *MockHistory_ListSummaries_Call) Run(run func(ctx context.Context, filter accountsummary.SummaryFilter)) *MockHistory_ListSummaries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(accountsummary.SummaryFilter))
+	})
+	return _c
+}
+
+func (_c *MockHistory_ListSummaries_Call) Return(_a0 []model.StoredSummary, _a1 error) *MockHistory_ListSummaries_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockHistory_ListSummaries_Call) RunAndReturn(run func(context.Context, accountsummary.SummaryFilter) ([]model.StoredSummary, error)) *MockHistory_ListSummaries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListTransactions provides a mock function with given fields: ctx, executionID
+func (_m *MockHistory) ListTransactions(ctx context.Context, executionID string) ([]model.Transaction, error) {
+	ret := _m.Called(ctx, executionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListTransactions")
+	}
+
+	var r0 []model.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]model.Transaction, error)); ok {
+		return rf(ctx, executionID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []model.Transaction); ok {
+		r0 = rf(ctx, executionID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, executionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockHistory_ListTransactions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListTransactions'
+type MockHistory_ListTransactions_Call struct {
+	*mock.Call
+}
+
+// ListTransactions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - executionID string
+func (_e *MockHistory_Expecter) ListTransactions(ctx interface{}, executionID interface{}) *MockHistory_ListTransactions_Call {
+	return &MockHistory_ListTransactions_Call{Call: _e.mock.On("ListTransactions", ctx, executionID)}
+}
+
+func (_c *MockHistory_ListTransactions_Call) Run(run func(ctx context.Context, executionID string)) *MockHistory_ListTransactions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockHistory_ListTransactions_Call) Return(_a0 []model.Transaction, _a1 error) *MockHistory_ListTransactions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockHistory_ListTransactions_Call) RunAndReturn(run func(context.Context, string) ([]model.Transaction, error)) *MockHistory_ListTransactions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockHistory creates a new instance of MockHistory. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockHistory(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockHistory {
+	mock := &MockHistory{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,305 @@
+// Code generated by mockery v2.46.3. DO NOT EDIT.
+
+package accountsummary
+
+import (
+	context "context"
+	model "stori/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// MockOutbox is an autogenerated mock type for the Outbox type
+type MockOutbox struct {
+	mock.Mock
+}
+
+type MockOutbox_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockOutbox) EXPECT() *MockOutbox_Expecter {
+	return &MockOutbox_Expecter{mock: &_m.Mock}
+}
+
+// Claim provides a mock function with given fields: ctx, limit, lease
+func (_m *MockOutbox) Claim(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxEmail, error) {
+	ret := _m.Called(ctx, limit, lease)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Claim")
+	}
+
+	var r0 []model.OutboxEmail
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Duration) ([]model.OutboxEmail, error)); ok {
+		return rf(ctx, limit, lease)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Duration) []model.OutboxEmail); ok {
+		r0 = rf(ctx, limit, lease)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.OutboxEmail)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, time.Duration) error); ok {
+		r1 = rf(ctx, limit, lease)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockOutbox_Claim_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Claim'
+type MockOutbox_Claim_Call struct {
+	*mock.Call
+}
+
+// Claim is a helper method to define mock.On call
+//   - ctx context.Context
+//   - limit int
+//   - lease time.Duration
+func (_e *MockOutbox_Expecter) Claim(ctx interface{}, limit interface{}, lease interface{}) *MockOutbox_Claim_Call {
+	return &MockOutbox_Claim_Call{Call: _e.mock.On("Claim", ctx, limit, lease)}
+}
+
+func (_c *MockOutbox_Claim_Call) Run(run func(ctx context.Context, limit int, lease time.Duration)) *MockOutbox_Claim_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockOutbox_Claim_Call) Return(_a0 []model.OutboxEmail, _a1 error) *MockOutbox_Claim_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockOutbox_Claim_Call) RunAndReturn(run func(context.Context, int, time.Duration) ([]model.OutboxEmail, error)) *MockOutbox_Claim_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ClaimSummary provides a mock function with given fields: ctx, summaryID, lease
+func (_m *MockOutbox) ClaimSummary(ctx context.Context, summaryID int64, lease time.Duration) ([]model.OutboxEmail, error) {
+	ret := _m.Called(ctx, summaryID, lease)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimSummary")
+	}
+
+	var r0 []model.OutboxEmail
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Duration) ([]model.OutboxEmail, error)); ok {
+		return rf(ctx, summaryID, lease)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Duration) []model.OutboxEmail); ok {
+		r0 = rf(ctx, summaryID, lease)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.OutboxEmail)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, time.Duration) error); ok {
+		r1 = rf(ctx, summaryID, lease)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockOutbox_ClaimSummary_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ClaimSummary'
+type MockOutbox_ClaimSummary_Call struct {
+	*mock.Call
+}
+
+// ClaimSummary is a helper method to define mock.On call
+//   - ctx context.Context
+//   - summaryID int64
+//   - lease time.Duration
+func (_e *MockOutbox_Expecter) ClaimSummary(ctx interface{}, summaryID interface{}, lease interface{}) *MockOutbox_ClaimSummary_Call {
+	return &MockOutbox_ClaimSummary_Call{Call: _e.mock.On("ClaimSummary", ctx, summaryID, lease)}
+}
+
+func (_c *MockOutbox_ClaimSummary_Call) Run(run func(ctx context.Context, summaryID int64, lease time.Duration)) *MockOutbox_ClaimSummary_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockOutbox_ClaimSummary_Call) Return(_a0 []model.OutboxEmail, _a1 error) *MockOutbox_ClaimSummary_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockOutbox_ClaimSummary_Call) RunAndReturn(run func(context.Context, int64, time.Duration) ([]model.OutboxEmail, error)) *MockOutbox_ClaimSummary_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkFailed provides a mock function with given fields: ctx, id, attempts, lastErr
+func (_m *MockOutbox) MarkFailed(ctx context.Context, id int64, attempts int, lastErr string) error {
+	ret := _m.Called(ctx, id, attempts, lastErr)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkFailed")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int, string) error); ok {
+		r0 = rf(ctx, id, attempts, lastErr)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockOutbox_MarkFailed_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkFailed'
+type MockOutbox_MarkFailed_Call struct {
+	*mock.Call
+}
+
+// MarkFailed is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+//   - attempts int
+//   - lastErr string
+func (_e *MockOutbox_Expecter) MarkFailed(ctx interface{}, id interface{}, attempts interface{}, lastErr interface{}) *MockOutbox_MarkFailed_Call {
+	return &MockOutbox_MarkFailed_Call{Call: _e.mock.On("MarkFailed", ctx, id, attempts, lastErr)}
+}
+
+func (_c *MockOutbox_MarkFailed_Call) Run(run func(ctx context.Context, id int64, attempts int, lastErr string)) *MockOutbox_MarkFailed_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockOutbox_MarkFailed_Call) Return(_a0 error) *MockOutbox_MarkFailed_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockOutbox_MarkFailed_Call) RunAndReturn(run func(context.Context, int64, int, string) error) *MockOutbox_MarkFailed_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkRetry provides a mock function with given fields: ctx, id, attempts, lastErr, nextAttemptAt
+func (_m *MockOutbox) MarkRetry(ctx context.Context, id int64, attempts int, lastErr string, nextAttemptAt time.Time) error {
+	ret := _m.Called(ctx, id, attempts, lastErr, nextAttemptAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkRetry")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int, string, time.Time) error); ok {
+		r0 = rf(ctx, id, attempts, lastErr, nextAttemptAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockOutbox_MarkRetry_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkRetry'
+type MockOutbox_MarkRetry_Call struct {
+	*mock.Call
+}
+
+// MarkRetry is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+//   - attempts int
+//   - lastErr string
+//   - nextAttemptAt time.Time
+func (_e *MockOutbox_Expecter) MarkRetry(ctx interface{}, id interface{}, attempts interface{}, lastErr interface{}, nextAttemptAt interface{}) *MockOutbox_MarkRetry_Call {
+	return &MockOutbox_MarkRetry_Call{Call: _e.mock.On("MarkRetry", ctx, id, attempts, lastErr, nextAttemptAt)}
+}
+
+func (_c *MockOutbox_MarkRetry_Call) Run(run func(ctx context.Context, id int64, attempts int, lastErr string, nextAttemptAt time.Time)) *MockOutbox_MarkRetry_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int), args[3].(string), args[4].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockOutbox_MarkRetry_Call) Return(_a0 error) *MockOutbox_MarkRetry_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockOutbox_MarkRetry_Call) RunAndReturn(run func(context.Context, int64, int, string, time.Time) error) *MockOutbox_MarkRetry_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkSent provides a mock function with given fields: ctx, id
+func (_m *MockOutbox) MarkSent(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkSent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockOutbox_MarkSent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkSent'
+type MockOutbox_MarkSent_Call struct {
+	*mock.Call
+}
+
+// MarkSent is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+func (_e *MockOutbox_Expecter) MarkSent(ctx interface{}, id interface{}) *MockOutbox_MarkSent_Call {
+	return &MockOutbox_MarkSent_Call{Call: _e.mock.On("MarkSent", ctx, id)}
+}
+
+func (_c *MockOutbox_MarkSent_Call) Run(run func(ctx context.Context, id int64)) *MockOutbox_MarkSent_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockOutbox_MarkSent_Call) Return(_a0 error) *MockOutbox_MarkSent_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockOutbox_MarkSent_Call) RunAndReturn(run func(context.Context, int64) error) *MockOutbox_MarkSent_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockOutbox creates a new instance of MockOutbox. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockOutbox(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockOutbox {
+	mock := &MockOutbox{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,88 @@
+// Code generated by mockery v2.46.3. DO NOT EDIT.
+
+package accountsummary
+
+import mock "github.com/stretchr/testify/mock"
+
+// MockRecipientDirectory is an autogenerated mock type for the RecipientDirectory type
+type MockRecipientDirectory struct {
+	mock.Mock
+}
+
+type MockRecipientDirectory_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRecipientDirectory) EXPECT() *MockRecipientDirectory_Expecter {
+	return &MockRecipientDirectory_Expecter{mock: &_m.Mock}
+}
+
+// Email provides a mock function with given fields: account
+func (_m *MockRecipientDirectory) Email(account string) (string, error) {
+	ret := _m.Called(account)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Email")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (string, error)); ok {
+		return rf(account)
+	}
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(account)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(account)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRecipientDirectory_Email_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Email'
+type MockRecipientDirectory_Email_Call struct {
+	*mock.Call
+}
+
+// Email is a helper method to define mock.On call
+//   - account string
+func (_e *MockRecipientDirectory_Expecter) Email(account interface{}) *MockRecipientDirectory_Email_Call {
+	return &MockRecipientDirectory_Email_Call{Call: _e.mock.On("Email", account)}
+}
+
+func (_c *MockRecipientDirectory_Email_Call) Run(run func(account string)) *MockRecipientDirectory_Email_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockRecipientDirectory_Email_Call) Return(_a0 string, _a1 error) *MockRecipientDirectory_Email_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockRecipientDirectory_Email_Call) RunAndReturn(run func(string) (string, error)) *MockRecipientDirectory_Email_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRecipientDirectory creates a new instance of MockRecipientDirectory. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRecipientDirectory(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRecipientDirectory {
+	mock := &MockRecipientDirectory{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -3,6 +3,7 @@
 package accountsummary
 
 import (
+	context "context"
 	accountsummary "stori/accountsummary"
 
 	mock "github.com/stretchr/testify/mock"
@@ -21,22 +22,32 @@ func (_m *MockRepository) EXPECT() *MockRepository_Expecter {
 	return &MockRepository_Expecter{mock: &_m.Mock}
 }
 
-// Create provides a mock function with given fields: execution
-func (_m *MockRepository) Create(execution accountsummary.Execution) error {
-	ret := _m.Called(execution)
+// Create provides a mock function with given fields: ctx, execution
+func (_m *MockRepository) Create(ctx context.Context, execution accountsummary.Execution) (int64, error) {
+	ret := _m.Called(ctx, execution)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Create")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(accountsummary.Execution) error); ok {
-		r0 = rf(execution)
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, accountsummary.Execution) (int64, error)); ok {
+		return rf(ctx, execution)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, accountsummary.Execution) int64); ok {
+		r0 = rf(ctx, execution)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(int64)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, accountsummary.Execution) error); ok {
+		r1 = rf(ctx, execution)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // MockRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
@@ -45,24 +56,84 @@ type MockRepository_Create_Call struct {
 }
 
 // Create is a helper method to define mock.On call
+//   - ctx context.Context
 //   - execution accountsummary.Execution
-func (_e *MockRepository_Expecter) Create(execution interface{}) *MockRepository_Create_Call {
-	return &MockRepository_Create_Call{Call: _e.mock.On("Create", execution)}
+func (_e *MockRepository_Expecter) Create(ctx interface{}, execution interface{}) *MockRepository_Create_Call {
+	return &MockRepository_Create_Call{Call: _e.mock.On("Create", ctx, execution)}
+}
+
+func (_c *MockRepository_Create_Call) Run(run func(ctx context.Context, execution accountsummary.Execution)) *MockRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(accountsummary.Execution))
+	})
+	return _c
+}
+
+func (_c *MockRepository_Create_Call) Return(_a0 int64, _a1 error) *MockRepository_Create_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockRepository_Create_Call) RunAndReturn(run func(context.Context, accountsummary.Execution) (int64, error)) *MockRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WasProcessed provides a mock function with given fields: ctx, email, account, checksum
+func (_m *MockRepository) WasProcessed(ctx context.Context, email string, account string, checksum string) (bool, error) {
+	ret := _m.Called(ctx, email, account, checksum)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WasProcessed")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (bool, error)); ok {
+		return rf(ctx, email, account, checksum)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) bool); ok {
+		r0 = rf(ctx, email, account, checksum)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, email, account, checksum)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepository_WasProcessed_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WasProcessed'
+type MockRepository_WasProcessed_Call struct {
+	*mock.Call
+}
+
+// WasProcessed is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+//   - account string
+//   - checksum string
+func (_e *MockRepository_Expecter) WasProcessed(ctx interface{}, email interface{}, account interface{}, checksum interface{}) *MockRepository_WasProcessed_Call {
+	return &MockRepository_WasProcessed_Call{Call: _e.mock.On("WasProcessed", ctx, email, account, checksum)}
 }
 
-func (_c *MockRepository_Create_Call) Run(run func(execution accountsummary.Execution)) *MockRepository_Create_Call {
+func (_c *MockRepository_WasProcessed_Call) Run(run func(ctx context.Context, email string, account string, checksum string)) *MockRepository_WasProcessed_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(accountsummary.Execution))
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
 	})
 	return _c
 }
 
-func (_c *MockRepository_Create_Call) Return(_a0 error) *MockRepository_Create_Call {
-	_c.Call.Return(_a0)
+func (_c *MockRepository_WasProcessed_Call) Return(_a0 bool, _a1 error) *MockRepository_WasProcessed_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockRepository_Create_Call) RunAndReturn(run func(accountsummary.Execution) error) *MockRepository_Create_Call {
+func (_c *MockRepository_WasProcessed_Call) RunAndReturn(run func(context.Context, string, string, string) (bool, error)) *MockRepository_WasProcessed_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -3,9 +3,12 @@
 package accountsummary
 
 import (
-	model "stori/model"
+	context "context"
+	iter "iter"
 
 	mock "github.com/stretchr/testify/mock"
+
+	model "stori/model"
 )
 
 // MockTransactionsReader is an autogenerated mock type for the TransactionsReader type
@@ -21,59 +24,50 @@ func (_m *MockTransactionsReader) EXPECT() *MockTransactionsReader_Expecter {
 	return &MockTransactionsReader_Expecter{mock: &_m.Mock}
 }
 
-// ReadTransactions provides a mock function with given fields:
-func (_m *MockTransactionsReader) ReadTransactions() ([]model.Transaction, error) {
-	ret := _m.Called()
+// StreamTransactions provides a mock function with given fields: ctx
+func (_m *MockTransactionsReader) StreamTransactions(ctx context.Context) iter.Seq2[model.Transaction, error] {
+	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ReadTransactions")
+		panic("no return value specified for StreamTransactions")
 	}
 
-	var r0 []model.Transaction
-	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]model.Transaction, error)); ok {
-		return rf()
-	}
-	if rf, ok := ret.Get(0).(func() []model.Transaction); ok {
-		r0 = rf()
+	var r0 iter.Seq2[model.Transaction, error]
+	if rf, ok := ret.Get(0).(func(context.Context) iter.Seq2[model.Transaction, error]); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]model.Transaction)
+			r0 = ret.Get(0).(iter.Seq2[model.Transaction, error])
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
-	} else {
-		r1 = ret.Error(1)
-	}
-
-	return r0, r1
+	return r0
 }
 
-// MockTransactionsReader_ReadTransactions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReadTransactions'
-type MockTransactionsReader_ReadTransactions_Call struct {
+// MockTransactionsReader_StreamTransactions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StreamTransactions'
+type MockTransactionsReader_StreamTransactions_Call struct {
 	*mock.Call
 }
 
-// ReadTransactions is a helper method to define mock.On call
-func (_e *MockTransactionsReader_Expecter) ReadTransactions() *MockTransactionsReader_ReadTransactions_Call {
-	return &MockTransactionsReader_ReadTransactions_Call{Call: _e.mock.On("ReadTransactions")}
+// StreamTransactions is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockTransactionsReader_Expecter) StreamTransactions(ctx interface{}) *MockTransactionsReader_StreamTransactions_Call {
+	return &MockTransactionsReader_StreamTransactions_Call{Call: _e.mock.On("StreamTransactions", ctx)}
 }
 
-func (_c *MockTransactionsReader_ReadTransactions_Call) Run(run func()) *MockTransactionsReader_ReadTransactions_Call {
+func (_c *MockTransactionsReader_StreamTransactions_Call) Run(run func(ctx context.Context)) *MockTransactionsReader_StreamTransactions_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(context.Context))
 	})
 	return _c
 }
 
-func (_c *MockTransactionsReader_ReadTransactions_Call) Return(_a0 []model.Transaction, _a1 error) *MockTransactionsReader_ReadTransactions_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *MockTransactionsReader_StreamTransactions_Call) Return(_a0 iter.Seq2[model.Transaction, error]) *MockTransactionsReader_StreamTransactions_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockTransactionsReader_ReadTransactions_Call) RunAndReturn(run func() ([]model.Transaction, error)) *MockTransactionsReader_ReadTransactions_Call {
+func (_c *MockTransactionsReader_StreamTransactions_Call) RunAndReturn(run func(context.Context) iter.Seq2[model.Transaction, error]) *MockTransactionsReader_StreamTransactions_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -8,8 +8,15 @@ import (
 
 type AccountSummary struct {
 	Email                string
+	Account              string
 	TotalBalance         decimal.Decimal
 	AverageDebitAmount   decimal.Decimal
 	AverageCreditAmount  decimal.Decimal
-	TransactionsPerMonth map[time.Month]int
+	TransactionsPerMonth map[YearMonth]int
+	MinimumBalance       decimal.Decimal
+	MinimumBalanceDate   time.Time
+	MaximumBalance       decimal.Decimal
+	MaximumBalanceDate   time.Time
+	DailyBalances        []DailyBalance
+	MonthlyBreakdown     []PeriodBreakdown
 }
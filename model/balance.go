@@ -0,0 +1,13 @@
+package model
+
+import (
+	"time"
+
+	"github.com/govalues/decimal"
+)
+
+// DailyBalance is the balance of the account at the end of a day.
+type DailyBalance struct {
+	Date    time.Time
+	Balance decimal.Decimal
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// Statuses of executions. An execution is running until its summary and transactions are stored, and failed when
+// they could not be.
+const (
+	ExecutionRunning   = "running"
+	ExecutionCompleted = "completed"
+	ExecutionFailed    = "failed"
+)
+
+// ExecutionRecord describes a past run of the app over a source, as stored by the repository.
+// Checksum is empty when the source could not be fingerprinted.
+type ExecutionRecord struct {
+	ID         string
+	SourceURI  string
+	Checksum   string
+	Status     string
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// StoredSummary is an account summary read back from the repository, with the execution that produced it.
+type StoredSummary struct {
+	ID        int64
+	CreatedAt time.Time
+	Execution ExecutionRecord
+	Summary   AccountSummary
+}
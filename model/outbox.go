@@ -0,0 +1,16 @@
+package model
+
+// Statuses of the emails of the outbox. Pending emails are sent, or retried, by the dispatcher.
+const (
+	OutboxPending = "pending"
+	OutboxSent    = "sent"
+	OutboxFailed  = "failed"
+)
+
+// OutboxEmail is the email of a stored summary, queued along with it until it is sent.
+// Attempts counts the failed sends so far.
+type OutboxEmail struct {
+	ID       int64
+	Attempts int
+	Summary  AccountSummary
+}
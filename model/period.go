@@ -0,0 +1,16 @@
+package model
+
+import "github.com/govalues/decimal"
+
+// PeriodBreakdown summarizes the credits and debits of a period. Debit amounts are negative, as in the
+// transactions, and averages are zero when the period has no transaction of that kind.
+type PeriodBreakdown struct {
+	Period              YearMonth
+	CreditCount         int
+	DebitCount          int
+	TotalCredit         decimal.Decimal
+	TotalDebit          decimal.Decimal
+	NetFlow             decimal.Decimal
+	AverageCreditAmount decimal.Decimal
+	AverageDebitAmount  decimal.Decimal
+}
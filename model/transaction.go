@@ -10,4 +10,9 @@ type Transaction struct {
 	ID     int
 	Date   time.Time
 	Amount decimal.Decimal
+	// Account identifies the account or customer of the transaction in files consolidating several of them.
+	// It is empty otherwise.
+	Account string
+	// Attributes holds the extra columns of the source that were asked to be kept, by column name.
+	Attributes map[string]string
 }
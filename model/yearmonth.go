@@ -0,0 +1,49 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const yearMonthLayout = "2006-01"
+
+var ErrInvalidYearMonth = errors.New("invalid year-month")
+
+// YearMonth identifies a calendar month of a given year, so that the same month of different years is never merged.
+// It is encoded as text in the "2006-01" format, e.g. when used as a JSON object key.
+type YearMonth struct {
+	Year  int
+	Month time.Month
+}
+
+func YearMonthOf(date time.Time) YearMonth {
+	return YearMonth{Year: date.Year(), Month: date.Month()}
+}
+
+func (ym YearMonth) Before(other YearMonth) bool {
+	if ym.Year != other.Year {
+		return ym.Year < other.Year
+	}
+
+	return ym.Month < other.Month
+}
+
+func (ym YearMonth) String() string {
+	return fmt.Sprintf("%04d-%02d", ym.Year, int(ym.Month))
+}
+
+func (ym YearMonth) MarshalText() ([]byte, error) {
+	return []byte(ym.String()), nil
+}
+
+func (ym *YearMonth) UnmarshalText(text []byte) error {
+	date, err := time.Parse(yearMonthLayout, string(text))
+	if err != nil {
+		return fmt.Errorf("model: YearMonth: UnmarshalText %s: %w", text, ErrInvalidYearMonth)
+	}
+
+	*ym = YearMonthOf(date)
+
+	return nil
+}
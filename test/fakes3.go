@@ -0,0 +1,141 @@
+package test
+
+import (
+	"bytes"
+	"crypto/md5" //nolint:gosec // S3 ETags are the MD5 of the content
+	"encoding/hex"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+type (
+	// FakeS3 is a local stand-in for S3 serving the objects put in it, with path-style addressing.
+	// It answers HEAD, GET, ranged GET and GET ?tagging requests on objects, which is what the app uses.
+	FakeS3 struct {
+		server  *httptest.Server
+		mu      sync.Mutex
+		objects map[string]FakeObject
+		ranges  []string
+	}
+
+	FakeObject struct {
+		Content []byte
+		// Metadata is the user metadata of the object, sent as x-amz-meta-* headers.
+		Metadata map[string]string
+		Tags     map[string]string
+		// TaggingDenied answers the tagging requests of the object with AccessDenied, as S3 does for callers without
+		// the s3:GetObjectTagging permission.
+		TaggingDenied bool
+	}
+
+	fakeTagging struct {
+		XMLName xml.Name  `xml:"Tagging"`
+		Tags    []fakeTag `xml:"TagSet>Tag"`
+	}
+
+	fakeTag struct {
+		Key   string `xml:"Key"`
+		Value string `xml:"Value"`
+	}
+)
+
+// NewFakeS3 starts a fake S3 server, stopped when the test ends.
+func NewFakeS3(t *testing.T) *FakeS3 {
+	t.Helper()
+
+	fake := &FakeS3{objects: make(map[string]FakeObject)}
+	fake.server = httptest.NewServer(http.HandlerFunc(fake.serve))
+	t.Cleanup(fake.server.Close)
+
+	return fake
+}
+
+func (fake *FakeS3) Put(bucket, key string, object FakeObject) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	fake.objects[bucket+"/"+key] = object
+}
+
+// Ranges are the Range headers of the GET requests received so far.
+func (fake *FakeS3) Ranges() []string {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	return append([]string(nil), fake.ranges...)
+}
+
+func (fake *FakeS3) URL() string {
+	return fake.server.URL
+}
+
+// Client is an S3 client of the fake server.
+func (fake *FakeS3) Client() *s3.S3 {
+	return s3.New(session.Must(session.NewSession(&aws.Config{
+		Endpoint:         aws.String(fake.server.URL),
+		Region:           aws.String("us-east-1"),
+		Credentials:      credentials.NewStaticCredentials("fake", "fake", ""),
+		S3ForcePathStyle: aws.Bool(true),
+	})))
+}
+
+func (fake *FakeS3) serve(w http.ResponseWriter, r *http.Request) {
+	fake.mu.Lock()
+	object, ok := fake.objects[strings.TrimPrefix(r.URL.Path, "/")]
+	fake.mu.Unlock()
+
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`))
+
+		return
+	}
+
+	if _, tagging := r.URL.Query()["tagging"]; tagging && r.Method == http.MethodGet {
+		if object.TaggingDenied {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`))
+
+			return
+		}
+
+		tags := fakeTagging{}
+		for key, value := range object.Tags {
+			tags.Tags = append(tags.Tags, fakeTag{Key: key, Value: value})
+		}
+
+		_ = xml.NewEncoder(w).Encode(tags)
+
+		return
+	}
+
+	if r.Method != http.MethodHead && r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if requested := r.Header.Get("Range"); requested != "" {
+		fake.mu.Lock()
+		fake.ranges = append(fake.ranges, requested)
+		fake.mu.Unlock()
+	}
+
+	sum := md5.Sum(object.Content) //nolint:gosec // S3 ETags are the MD5 of the content
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+	for key, value := range object.Metadata {
+		w.Header().Set("x-amz-meta-"+key, value)
+	}
+
+	// ServeContent answers Range and If-Match requests as S3 does.
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(object.Content))
+}
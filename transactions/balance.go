@@ -0,0 +1,105 @@
+package transactions
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/govalues/decimal"
+
+	"stori/model"
+)
+
+// dayMovements accumulates the transactions of a single day in the order they are read.
+// lowest and highest are the extremes of the running sum within the day, after each transaction.
+type dayMovements struct {
+	net     decimal.Decimal
+	lowest  decimal.Decimal
+	highest decimal.Decimal
+}
+
+// balanceTracker keeps one entry per day instead of one per transaction, so that the running balance can be
+// computed in date order once the stream is over without holding every transaction in memory.
+// Transactions of the same day are taken in the order they are read.
+type balanceTracker struct {
+	days map[time.Time]dayMovements
+}
+
+func newBalanceTracker() balanceTracker {
+	return balanceTracker{days: make(map[time.Time]dayMovements)}
+}
+
+func (tracker balanceTracker) add(transaction model.Transaction) error {
+	fail := func(err error) error {
+		return fmt.Errorf("transactions: balanceTracker: add: %w", err)
+	}
+
+	date := transaction.Date
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	movements, seen := tracker.days[day]
+
+	net, err := movements.net.Add(transaction.Amount)
+	if err != nil {
+		return fail(err)
+	}
+
+	movements.net = net
+	if !seen || net.Less(movements.lowest) {
+		movements.lowest = net
+	}
+	if !seen || movements.highest.Less(net) {
+		movements.highest = net
+	}
+
+	tracker.days[day] = movements
+
+	return nil
+}
+
+// apply walks the days in order to set the balance extremes and the end-of-day series of the results.
+// The account is assumed to start at zero. Ties are resolved in favor of the earliest date.
+func (tracker balanceTracker) apply(res ExecutionResults) (ExecutionResults, error) {
+	fail := func(err error) (ExecutionResults, error) {
+		return ExecutionResults{}, fmt.Errorf("transactions: balanceTracker: apply: %w", err)
+	}
+
+	days := make([]time.Time, 0, len(tracker.days))
+	for day := range tracker.days {
+		days = append(days, day)
+	}
+	slices.SortFunc(days, func(a, b time.Time) int { return a.Compare(b) })
+
+	balance := decimal.Zero
+	res.DailyBalances = make([]model.DailyBalance, 0, len(days))
+
+	for i, day := range days {
+		movements := tracker.days[day]
+
+		lowest, err := balance.Add(movements.lowest)
+		if err != nil {
+			return fail(err)
+		}
+
+		highest, err := balance.Add(movements.highest)
+		if err != nil {
+			return fail(err)
+		}
+
+		if i == 0 || lowest.Less(res.MinimumBalance) {
+			res.MinimumBalance, res.MinimumBalanceDate = lowest, day
+		}
+
+		if i == 0 || res.MaximumBalance.Less(highest) {
+			res.MaximumBalance, res.MaximumBalanceDate = highest, day
+		}
+
+		if balance, err = balance.Add(movements.net); err != nil {
+			return fail(err)
+		}
+
+		res.DailyBalances = append(res.DailyBalances, model.DailyBalance{Date: day, Balance: balance})
+	}
+
+	return res, nil
+}
@@ -24,7 +24,7 @@ func TestCountTransactionsByMonth_WhenNoTransactions(t *testing.T) {
 	// Assert
 	require.NoError(t, err)
 	for i := time.January; i <= time.December; i++ {
-		assert.Equal(t, 0, results.TransactionsPerMonth[i])
+		assert.Equal(t, 0, results.TransactionsPerMonth[model.YearMonth{Year: 2024, Month: i}])
 	}
 }
 
@@ -65,16 +65,58 @@ func TestCountTransactionsByMonth_SeveralTransactions(t *testing.T) {
 
 	// Assert
 	require.NoError(t, err)
-	assert.Equal(t, 2, results.TransactionsPerMonth[time.January])
-	assert.Equal(t, 1, results.TransactionsPerMonth[time.February])
-	assert.Equal(t, 0, results.TransactionsPerMonth[time.March])
-	assert.Equal(t, 0, results.TransactionsPerMonth[time.April])
-	assert.Equal(t, 0, results.TransactionsPerMonth[time.May])
-	assert.Equal(t, 0, results.TransactionsPerMonth[time.June])
-	assert.Equal(t, 0, results.TransactionsPerMonth[time.July])
-	assert.Equal(t, 0, results.TransactionsPerMonth[time.August])
-	assert.Equal(t, 0, results.TransactionsPerMonth[time.September])
-	assert.Equal(t, 0, results.TransactionsPerMonth[time.October])
-	assert.Equal(t, 2, results.TransactionsPerMonth[time.November])
-	assert.Equal(t, 0, results.TransactionsPerMonth[time.December])
+	assert.Equal(t, 2, results.TransactionsPerMonth[yearMonth2024(time.January)])
+	assert.Equal(t, 1, results.TransactionsPerMonth[yearMonth2024(time.February)])
+	assert.Equal(t, 0, results.TransactionsPerMonth[yearMonth2024(time.March)])
+	assert.Equal(t, 0, results.TransactionsPerMonth[yearMonth2024(time.April)])
+	assert.Equal(t, 0, results.TransactionsPerMonth[yearMonth2024(time.May)])
+	assert.Equal(t, 0, results.TransactionsPerMonth[yearMonth2024(time.June)])
+	assert.Equal(t, 0, results.TransactionsPerMonth[yearMonth2024(time.July)])
+	assert.Equal(t, 0, results.TransactionsPerMonth[yearMonth2024(time.August)])
+	assert.Equal(t, 0, results.TransactionsPerMonth[yearMonth2024(time.September)])
+	assert.Equal(t, 0, results.TransactionsPerMonth[yearMonth2024(time.October)])
+	assert.Equal(t, 2, results.TransactionsPerMonth[yearMonth2024(time.November)])
+	assert.Equal(t, 0, results.TransactionsPerMonth[yearMonth2024(time.December)])
+}
+
+func TestCountTransactionsByMonth_WhenSpanningYears_SameMonthNotMerged(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	transactions := []model.Transaction{
+		{
+			ID:     1,
+			Date:   time.Date(2023, time.January, 15, 0, 0, 0, 0, time.UTC),
+			Amount: decimal.MustNew(100, 0),
+		},
+		{
+			ID:     2,
+			Date:   time.Date(2023, time.December, 20, 0, 0, 0, 0, time.UTC),
+			Amount: decimal.MustNew(100, 0),
+		},
+		{
+			ID:     3,
+			Date:   time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC),
+			Amount: decimal.MustNew(100, 0),
+		},
+		{
+			ID:     4,
+			Date:   time.Date(2024, time.January, 28, 0, 0, 0, 0, time.UTC),
+			Amount: decimal.MustNew(100, 0),
+		},
+	}
+
+	// Act
+	results, err := trs.Process(transactions)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, results.TransactionsPerMonth, 3)
+	assert.Equal(t, 1, results.TransactionsPerMonth[model.YearMonth{Year: 2023, Month: time.January}])
+	assert.Equal(t, 1, results.TransactionsPerMonth[model.YearMonth{Year: 2023, Month: time.December}])
+	assert.Equal(t, 2, results.TransactionsPerMonth[model.YearMonth{Year: 2024, Month: time.January}])
+}
+
+func yearMonth2024(month time.Month) model.YearMonth {
+	return model.YearMonth{Year: 2024, Month: month}
 }
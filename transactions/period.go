@@ -0,0 +1,103 @@
+package transactions
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/govalues/decimal"
+
+	"stori/model"
+)
+
+// periodTracker accumulates the credits and debits of every month, so the breakdown can be built once the
+// stream is over.
+type periodTracker struct {
+	periods map[model.YearMonth]model.PeriodBreakdown
+}
+
+func newPeriodTracker() periodTracker {
+	return periodTracker{periods: make(map[model.YearMonth]model.PeriodBreakdown)}
+}
+
+func (tracker periodTracker) add(transaction model.Transaction) error {
+	fail := func(err error) error {
+		return fmt.Errorf("transactions: periodTracker: add: %w", err)
+	}
+
+	period := model.YearMonthOf(transaction.Date)
+	breakdown, seen := tracker.periods[period]
+	if !seen {
+		breakdown = model.PeriodBreakdown{
+			Period:      period,
+			TotalCredit: decimal.Zero,
+			TotalDebit:  decimal.Zero,
+			NetFlow:     decimal.Zero,
+		}
+	}
+
+	amount := transaction.Amount
+	var err error
+
+	if isCredit(amount) {
+		breakdown.CreditCount++
+		if breakdown.TotalCredit, err = breakdown.TotalCredit.Add(amount); err != nil {
+			return fail(err)
+		}
+	} else {
+		breakdown.DebitCount++
+		if breakdown.TotalDebit, err = breakdown.TotalDebit.Add(amount); err != nil {
+			return fail(err)
+		}
+	}
+
+	if breakdown.NetFlow, err = breakdown.NetFlow.Add(amount); err != nil {
+		return fail(err)
+	}
+
+	tracker.periods[period] = breakdown
+
+	return nil
+}
+
+// apply sets the monthly breakdown of the results, in chronological order, along with the monthly averages.
+func (tracker periodTracker) apply(res ExecutionResults) (ExecutionResults, error) {
+	fail := func(err error) (ExecutionResults, error) {
+		return ExecutionResults{}, fmt.Errorf("transactions: periodTracker: apply: %w", err)
+	}
+
+	res.MonthlyBreakdown = make([]model.PeriodBreakdown, 0, len(tracker.periods))
+
+	for _, breakdown := range tracker.periods {
+		var err error
+		if breakdown.AverageCreditAmount, err = average(breakdown.TotalCredit, breakdown.CreditCount); err != nil {
+			return fail(err)
+		}
+
+		if breakdown.AverageDebitAmount, err = average(breakdown.TotalDebit, breakdown.DebitCount); err != nil {
+			return fail(err)
+		}
+
+		res.MonthlyBreakdown = append(res.MonthlyBreakdown, breakdown)
+	}
+
+	slices.SortFunc(res.MonthlyBreakdown, func(a, b model.PeriodBreakdown) int {
+		switch {
+		case a.Period.Before(b.Period):
+			return -1
+		case b.Period.Before(a.Period):
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	return res, nil
+}
+
+func average(total decimal.Decimal, count int) (decimal.Decimal, error) {
+	if count == 0 {
+		return decimal.Zero, nil
+	}
+
+	return total.Quo(decimal.MustNew(int64(count), 0))
+}
@@ -0,0 +1,67 @@
+package transactions_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stori/model"
+	trs "stori/transactions"
+)
+
+func TestMonthlyBreakdown_WhenNoTransactions_Empty(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	var transactions []model.Transaction
+
+	// Act
+	results, err := trs.Process(transactions)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, results.MonthlyBreakdown)
+}
+
+func TestMonthlyBreakdown_WhenSeveralMonths_TotalsAndAveragesPerMonth(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	transactions := []model.Transaction{
+		{ID: 1, Date: time.Date(2024, time.January, 3, 0, 0, 0, 0, time.UTC), Amount: decimal.MustParse("-10.5")},
+		{ID: 2, Date: time.Date(2023, time.December, 31, 0, 0, 0, 0, time.UTC), Amount: decimal.MustParse("30")},
+		{ID: 3, Date: time.Date(2024, time.January, 9, 0, 0, 0, 0, time.UTC), Amount: decimal.MustParse("25")},
+		{ID: 4, Date: time.Date(2024, time.January, 20, 0, 0, 0, 0, time.UTC), Amount: decimal.MustParse("-20")},
+		{ID: 5, Date: time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC), Amount: decimal.MustParse("50")},
+	}
+
+	// Act
+	results, err := trs.Process(transactions)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, results.MonthlyBreakdown, 2)
+
+	december := results.MonthlyBreakdown[0]
+	assert.Equal(t, model.YearMonth{Year: 2023, Month: time.December}, december.Period)
+	assert.Equal(t, 2, december.CreditCount)
+	assert.Equal(t, 0, december.DebitCount)
+	assert.Equal(t, decimal.MustParse("80"), december.TotalCredit)
+	assert.Equal(t, decimal.Zero, december.TotalDebit)
+	assert.Equal(t, decimal.MustParse("80"), december.NetFlow)
+	assert.Equal(t, decimal.MustParse("40"), december.AverageCreditAmount)
+	assert.Equal(t, decimal.Zero, december.AverageDebitAmount)
+
+	january := results.MonthlyBreakdown[1]
+	assert.Equal(t, model.YearMonth{Year: 2024, Month: time.January}, january.Period)
+	assert.Equal(t, 1, january.CreditCount)
+	assert.Equal(t, 2, january.DebitCount)
+	assert.Equal(t, decimal.MustParse("25"), january.TotalCredit)
+	assert.Equal(t, decimal.MustParse("-30.5"), january.TotalDebit)
+	assert.Equal(t, decimal.MustParse("-5.5"), january.NetFlow)
+	assert.Equal(t, decimal.MustParse("25"), january.AverageCreditAmount)
+	assert.Equal(t, decimal.MustParse("-15.25"), january.AverageDebitAmount)
+}
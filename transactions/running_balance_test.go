@@ -0,0 +1,107 @@
+package transactions_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stori/model"
+	trs "stori/transactions"
+)
+
+func TestRunningBalance_WhenNoTransactions_ShouldReturnZero(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	var transactions []model.Transaction
+
+	// Act
+	results, err := trs.Process(transactions)
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, results.MinimumBalance.IsZero())
+	assert.True(t, results.MaximumBalance.IsZero())
+	assert.Empty(t, results.DailyBalances)
+}
+
+func TestRunningBalance_WhenUnsortedTransactions_ComputedInDateOrder(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	transactions := []model.Transaction{
+		{ID: 1, Date: buildDay(time.March, 10), Amount: decimal.MustNew(-300, 0)},
+		{ID: 2, Date: buildDay(time.March, 1), Amount: decimal.MustNew(200, 0)},
+		{ID: 3, Date: buildDay(time.March, 5), Amount: decimal.MustNew(50, 0)},
+		{ID: 4, Date: buildDay(time.March, 1), Amount: decimal.MustNew(25, 0)},
+	}
+
+	// Act
+	results, err := trs.Process(transactions)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, decimal.MustNew(-25, 0), results.MinimumBalance)
+	assert.Equal(t, buildDay(time.March, 10), results.MinimumBalanceDate)
+	assert.Equal(t, decimal.MustNew(275, 0), results.MaximumBalance)
+	assert.Equal(t, buildDay(time.March, 5), results.MaximumBalanceDate)
+	assert.Equal(t, []model.DailyBalance{
+		{Date: buildDay(time.March, 1), Balance: decimal.MustNew(225, 0)},
+		{Date: buildDay(time.March, 5), Balance: decimal.MustNew(275, 0)},
+		{Date: buildDay(time.March, 10), Balance: decimal.MustNew(-25, 0)},
+	}, results.DailyBalances)
+}
+
+func TestRunningBalance_WhenExtremeWithinTheDay_ConsideredAfterEachTransaction(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	transactions := []model.Transaction{
+		{ID: 1, Date: buildDay(time.June, 1), Amount: decimal.MustNew(100, 0)},
+		{ID: 2, Date: buildDay(time.June, 2), Amount: decimal.MustNew(-150, 0)},
+		{ID: 3, Date: buildDay(time.June, 2), Amount: decimal.MustNew(500, 0)},
+		{ID: 4, Date: buildDay(time.June, 2), Amount: decimal.MustNew(-400, 0)},
+	}
+
+	// Act
+	results, err := trs.Process(transactions)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, decimal.MustNew(-50, 0), results.MinimumBalance)
+	assert.Equal(t, buildDay(time.June, 2), results.MinimumBalanceDate)
+	assert.Equal(t, decimal.MustNew(450, 0), results.MaximumBalance)
+	assert.Equal(t, buildDay(time.June, 2), results.MaximumBalanceDate)
+	assert.Equal(t, []model.DailyBalance{
+		{Date: buildDay(time.June, 1), Balance: decimal.MustNew(100, 0)},
+		{Date: buildDay(time.June, 2), Balance: decimal.MustNew(50, 0)},
+	}, results.DailyBalances)
+}
+
+func TestRunningBalance_WhenSameExtremeTwice_EarliestDate(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	transactions := []model.Transaction{
+		{ID: 1, Date: buildDay(time.May, 1), Amount: decimal.MustNew(-10, 0)},
+		{ID: 2, Date: buildDay(time.May, 2), Amount: decimal.MustNew(10, 0)},
+		{ID: 3, Date: buildDay(time.May, 3), Amount: decimal.MustNew(-10, 0)},
+	}
+
+	// Act
+	results, err := trs.Process(transactions)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, decimal.MustNew(-10, 0), results.MinimumBalance)
+	assert.Equal(t, buildDay(time.May, 1), results.MinimumBalanceDate)
+	assert.Equal(t, decimal.MustNew(0, 0), results.MaximumBalance)
+	assert.Equal(t, buildDay(time.May, 2), results.MaximumBalanceDate)
+}
+
+func buildDay(month time.Month, day int) time.Time {
+	return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,57 @@
+package transactions_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stori/model"
+	trs "stori/transactions"
+)
+
+func TestProcessStream_WhenSeveralTransactions_SameResultsAsProcess(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	transactions := []model.Transaction{
+		{ID: 1, Date: time.Date(2024, time.January, 3, 0, 0, 0, 0, time.UTC), Amount: decimal.MustNew(1050, 2)},
+		{ID: 2, Date: time.Date(2024, time.January, 9, 0, 0, 0, 0, time.UTC), Amount: decimal.MustNew(-320, 2)},
+		{ID: 3, Date: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), Amount: decimal.MustNew(75, 0)},
+	}
+
+	expected, err := trs.Process(transactions)
+	require.NoError(t, err)
+
+	// Act
+	results, err := trs.ProcessStream(trs.Seq(transactions))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, expected, results)
+	assert.Equal(t, 3, results.TransactionsCount())
+}
+
+func TestProcessStream_WhenStreamFails_Error(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	errRead := errors.New("read failure")
+	stream := func(yield func(model.Transaction, error) bool) {
+		if !yield(model.Transaction{ID: 1, Date: time.Now(), Amount: decimal.One}, nil) {
+			return
+		}
+
+		yield(model.Transaction{}, errRead)
+	}
+
+	// Act
+	_, err := trs.ProcessStream(stream)
+
+	// Assert
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errRead)
+}
@@ -2,6 +2,7 @@ package transactions
 
 import (
 	"fmt"
+	"iter"
 	"time"
 
 	"github.com/govalues/decimal"
@@ -12,50 +13,105 @@ import (
 type ExecutionResults struct {
 	TotalBalance decimal.Decimal
 
-	TransactionsPerMonth map[time.Month]int
+	TransactionsPerMonth map[model.YearMonth]int
 
 	DebitTransactionsCount  int
 	CreditTransactionsCount int
 	TotalDebitAmount        decimal.Decimal
 	TotalCreditAmount       decimal.Decimal
 
-	MinimumBalance decimal.Decimal
+	// MinimumBalance and MaximumBalance are the extremes of the running balance after each transaction,
+	// in date order, and the dates they were first reached on.
+	MinimumBalance     decimal.Decimal
+	MinimumBalanceDate time.Time
+	MaximumBalance     decimal.Decimal
+	MaximumBalanceDate time.Time
+
+	DailyBalances []model.DailyBalance
+
+	MonthlyBreakdown []model.PeriodBreakdown
 }
 
 func newExecutionResults() ExecutionResults {
-	transPerMonth := make(map[time.Month]int)
-	for i := time.January; i <= time.December; i++ {
-		transPerMonth[i] = 0
-	}
-
 	return ExecutionResults{
 		TotalBalance:         decimal.Zero,
-		TransactionsPerMonth: make(map[time.Month]int),
+		TransactionsPerMonth: make(map[model.YearMonth]int),
 		TotalDebitAmount:     decimal.Zero,
 		TotalCreditAmount:    decimal.Zero,
 		MinimumBalance:       decimal.Zero,
+		MaximumBalance:       decimal.Zero,
+		DailyBalances:        []model.DailyBalance{},
+		MonthlyBreakdown:     []model.PeriodBreakdown{},
 	}
 }
 
 func Process(transactions []model.Transaction) (ExecutionResults, error) {
-	fail := func(err error) (ExecutionResults, error) {
+	results, err := ProcessStream(Seq(transactions))
+	if err != nil {
 		return ExecutionResults{}, fmt.Errorf("transactions: Process: %w", err)
 	}
 
+	return results, nil
+}
+
+// ProcessStream aggregates the transactions as they are yielded, so memory use does not grow with their number.
+// It stops at the first error yielded by the stream.
+func ProcessStream(transactions iter.Seq2[model.Transaction, error]) (ExecutionResults, error) {
+	fail := func(err error) (ExecutionResults, error) {
+		return ExecutionResults{}, fmt.Errorf("transactions: ProcessStream: %w", err)
+	}
+
 	res := newExecutionResults()
-	var err error
+	balances := newBalanceTracker()
+	periods := newPeriodTracker()
+
+	for transaction, err := range transactions {
+		if err != nil {
+			return fail(err)
+		}
 
-	for _, transaction := range transactions {
 		if res, err = accountTransaction(res, transaction); err != nil {
 			return fail(err)
 		}
 
-		res.TransactionsPerMonth[transaction.Date.Month()]++
+		if err = balances.add(transaction); err != nil {
+			return fail(err)
+		}
+
+		if err = periods.add(transaction); err != nil {
+			return fail(err)
+		}
+
+		res.TransactionsPerMonth[model.YearMonthOf(transaction.Date)]++
+	}
+
+	res, err := balances.apply(res)
+	if err != nil {
+		return fail(err)
+	}
+
+	if res, err = periods.apply(res); err != nil {
+		return fail(err)
 	}
 
 	return res, nil
 }
 
+// Seq adapts a slice of transactions to the stream consumed by ProcessStream.
+func Seq(transactions []model.Transaction) iter.Seq2[model.Transaction, error] {
+	return func(yield func(model.Transaction, error) bool) {
+		for _, transaction := range transactions {
+			if !yield(transaction, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (res ExecutionResults) TransactionsCount() int {
+	return res.CreditTransactionsCount + res.DebitTransactionsCount
+}
+
 func accountTransaction(res ExecutionResults, transaction model.Transaction) (ExecutionResults, error) {
 	fail := func(err error) (ExecutionResults, error) {
 		return ExecutionResults{}, fmt.Errorf("transactions: accountTransaction: %w", err)